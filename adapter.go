@@ -24,3 +24,29 @@ type Adapter interface {
 
 	Apply(ctx context.Context, migration Migration) error
 }
+
+// TransactionBeginner is an optional interface that can be implemented by adapter
+// to begin transaction using isolation level, read only and deferred constraints options.
+type TransactionBeginner interface {
+	BeginTransaction(ctx context.Context, options TransactionOptions) (Adapter, error)
+}
+
+// Savepointer is an optional interface that can be implemented by transaction adapter
+// to partially rollback a transaction using named savepoint.
+type Savepointer interface {
+	Savepoint(ctx context.Context, name string) error
+	RollbackTo(ctx context.Context, name string) error
+	Release(ctx context.Context, name string) error
+}
+
+// SchemaIntrospector is an optional interface that can be implemented by adapter
+// to inspect current database schema as create table and create index migrations.
+type SchemaIntrospector interface {
+	Introspect(ctx context.Context) ([]Migration, error)
+}
+
+// MigrationBuilder is an optional interface that can be implemented by adapter
+// to build migration statement without executing it.
+type MigrationBuilder interface {
+	BuildMigration(migration Migration) string
+}
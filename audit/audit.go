@@ -0,0 +1,393 @@
+// Package audit records changes of records to audit log table.
+//
+// Audit is enabled per record type by wrapping repository:
+//
+//	repo := audit.New(rel.New(adapter), User{}, Order{})
+//	ctx = audit.WithActor(ctx, "admin@example.com")
+//	repo.Update(ctx, &user, rel.Set("name", "Alice"))
+//
+// Every Insert, InsertAll, Update, Delete, Restore and RestoreAny of enabled record type writes an audit row
+// in the same transaction, containing table, primary values, actor from context and json diff of old and new values.
+// UpdateAny, DeleteAny and DeleteAll are not audited since the affected records are not known.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/go-rel/rel"
+)
+
+const auditTable = "audit_logs"
+
+// Actions recorded in audit log.
+const (
+	Insert  = "insert"
+	Update  = "update"
+	Delete  = "delete"
+	Restore = "restore"
+)
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx with actor that is recorded in audit log.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor inside context.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// Change of a field, old value is nil on insert and new value is nil on delete.
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Repository that writes audit log for enabled record types.
+type Repository struct {
+	rel.Repository
+	table string
+	types map[reflect.Type]bool
+}
+
+// Table sets custom audit log table name.
+func (r *Repository) Table(name string) {
+	r.table = name
+}
+
+// Now returns current time using clock of the wrapped repository.
+func (r Repository) Now() time.Time {
+	return rel.CurrentTime(r.Repository)
+}
+
+// Enable audit log for record types.
+func (r *Repository) Enable(records ...interface{}) {
+	for _, record := range records {
+		r.types[reflect.Indirect(reflect.ValueOf(record)).Type()] = true
+	}
+}
+
+// Schema creates audit log table.
+func (r Repository) Schema(schema *rel.Schema) {
+	schema.CreateTableIfNotExists(r.table, func(t *rel.Table) {
+		t.ID("id")
+		t.String("table_name")
+		t.String("primary_values")
+		t.String("action")
+		t.String("actor")
+		t.Text("changes")
+		t.DateTime("created_at")
+	})
+
+	schema.CreateIndex(r.table, r.table+"_record", []string{"table_name", "primary_values"})
+}
+
+// Insert a record and write audit log.
+func (r Repository) Insert(ctx context.Context, record interface{}, mutators ...rel.Mutator) error {
+	if record == nil {
+		return r.Repository.Insert(ctx, record, mutators...)
+	}
+
+	doc := rel.NewDocument(record)
+	if !r.enabled(doc) {
+		return r.Repository.Insert(ctx, record, mutators...)
+	}
+
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		if err := r.Repository.Insert(ctx, doc, mutators...); err != nil {
+			return err
+		}
+
+		return r.write(ctx, doc, Insert, nil, values(doc))
+	})
+}
+
+// MustInsert a record and write audit log.
+func (r Repository) MustInsert(ctx context.Context, record interface{}, mutators ...rel.Mutator) {
+	must(r.Insert(ctx, record, mutators...))
+}
+
+// InsertAll records and write audit log for each record.
+func (r Repository) InsertAll(ctx context.Context, records interface{}, mutators ...rel.Mutator) error {
+	col := rel.NewCollection(records)
+	if !r.collectionEnabled(col) {
+		return r.Repository.InsertAll(ctx, records, mutators...)
+	}
+
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		if err := r.Repository.InsertAll(ctx, col, mutators...); err != nil {
+			return err
+		}
+
+		for i := 0; i < col.Len(); i++ {
+			doc := col.Get(i)
+			if err := r.write(ctx, doc, Insert, nil, values(doc)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// MustInsertAll records and write audit log for each record.
+func (r Repository) MustInsertAll(ctx context.Context, records interface{}, mutators ...rel.Mutator) {
+	must(r.InsertAll(ctx, records, mutators...))
+}
+
+// Update a record and write audit log containing changed fields.
+// Previous values are loaded from database inside the same transaction before the record is updated.
+func (r Repository) Update(ctx context.Context, record interface{}, mutators ...rel.Mutator) error {
+	doc := rel.NewDocument(record)
+	if !r.enabled(doc) {
+		return r.Repository.Update(ctx, record, mutators...)
+	}
+
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		var (
+			prev    = rel.NewDocument(reflect.New(doc.ReflectValue().Type()).Interface())
+			filters = make([]rel.FilterQuery, len(doc.PrimaryFields()))
+		)
+
+		for i, field := range doc.PrimaryFields() {
+			filters[i] = rel.Eq(field, doc.PrimaryValues()[i])
+		}
+
+		if err := r.Find(ctx, prev, rel.Where(filters...).Unscoped()); err != nil {
+			return err
+		}
+
+		var (
+			old = values(prev)
+			ch  = rel.NewChangeset(prev)
+		)
+
+		if err := r.Repository.Update(ctx, doc, mutators...); err != nil {
+			return err
+		}
+
+		for _, field := range doc.Fields() {
+			value, _ := doc.Value(field)
+			prev.SetValue(field, value)
+		}
+
+		var (
+			oldChanged = make(map[string]interface{})
+			newChanged = make(map[string]interface{})
+		)
+
+		for _, field := range doc.Fields() {
+			if ch.FieldChanged(field) {
+				oldChanged[field] = old[field]
+				newChanged[field], _ = doc.Value(field)
+			}
+		}
+
+		return r.write(ctx, doc, Update, oldChanged, newChanged)
+	})
+}
+
+// MustUpdate a record and write audit log containing changed fields.
+func (r Repository) MustUpdate(ctx context.Context, record interface{}, mutators ...rel.Mutator) {
+	must(r.Update(ctx, record, mutators...))
+}
+
+// Delete a record and write audit log containing its last values.
+func (r Repository) Delete(ctx context.Context, record interface{}, mutators ...rel.Mutator) error {
+	doc := rel.NewDocument(record)
+	if !r.enabled(doc) {
+		return r.Repository.Delete(ctx, record, mutators...)
+	}
+
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		old := values(doc)
+
+		if err := r.Repository.Delete(ctx, doc, mutators...); err != nil {
+			return err
+		}
+
+		return r.write(ctx, doc, Delete, old, nil)
+	})
+}
+
+// MustDelete a record and write audit log containing its last values.
+func (r Repository) MustDelete(ctx context.Context, record interface{}, mutators ...rel.Mutator) {
+	must(r.Delete(ctx, record, mutators...))
+}
+
+// Restore a soft deleted record and write audit log containing restored fields.
+func (r Repository) Restore(ctx context.Context, record interface{}, mutators ...rel.Mutator) error {
+	doc := rel.NewDocument(record)
+	if !r.enabled(doc) {
+		return r.Repository.Restore(ctx, record, mutators...)
+	}
+
+	return r.Transaction(ctx, func(ctx context.Context) error {
+		old := values(doc)
+
+		if err := r.Repository.Restore(ctx, doc, mutators...); err != nil {
+			return err
+		}
+
+		oldChanged, newChanged := diff(old, values(doc))
+		return r.write(ctx, doc, Restore, oldChanged, newChanged)
+	})
+}
+
+// MustRestore a soft deleted record and write audit log containing restored fields.
+func (r Repository) MustRestore(ctx context.Context, record interface{}, mutators ...rel.Mutator) {
+	must(r.Restore(ctx, record, mutators...))
+}
+
+// RestoreAny soft deleted records that match the queriers and write audit log for each restored record.
+// Matching records are loaded inside the same transaction before they're restored.
+func (r Repository) RestoreAny(ctx context.Context, records interface{}, queriers ...rel.Querier) (int, error) {
+	col := rel.NewCollection(records)
+	if !r.collectionEnabled(col) {
+		return r.Repository.RestoreAny(ctx, records, queriers...)
+	}
+
+	var restoredCount int
+	err := r.Transaction(ctx, func(ctx context.Context) error {
+		var (
+			err      error
+			restored = rel.NewCollection(reflect.New(col.ReflectValue().Type()).Interface())
+		)
+
+		if err = r.FindAll(ctx, restored, append(queriers, rel.Unscoped(true))...); err != nil {
+			return err
+		}
+
+		if restoredCount, err = r.Repository.RestoreAny(ctx, records, queriers...); err != nil {
+			return err
+		}
+
+		for i := 0; i < restored.Len(); i++ {
+			doc := restored.Get(i)
+			old := values(doc)
+
+			doc.SetValue("deleted_at", nil)
+			doc.SetValue("deleted", false)
+			doc.SetValue("deleted_by", nil)
+
+			oldChanged, newChanged := diff(old, values(doc))
+			if len(newChanged) == 0 {
+				continue
+			}
+
+			if err := r.write(ctx, doc, Restore, oldChanged, newChanged); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return restoredCount, err
+}
+
+// MustRestoreAny soft deleted records that match the queriers and write audit log for each restored record.
+func (r Repository) MustRestoreAny(ctx context.Context, records interface{}, queriers ...rel.Querier) int {
+	restoredCount, err := r.RestoreAny(ctx, records, queriers...)
+	must(err)
+	return restoredCount
+}
+
+func (r Repository) enabled(doc *rel.Document) bool {
+	return r.types[doc.ReflectValue().Type()]
+}
+
+// collectionEnabled checks element type of the collection, which may be a struct or a pointer to struct.
+func (r Repository) collectionEnabled(col *rel.Collection) bool {
+	rt := col.ReflectValue().Type().Elem()
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+
+	return r.types[rt]
+}
+
+func (r Repository) write(ctx context.Context, doc *rel.Document, action string, old map[string]interface{}, new map[string]interface{}) error {
+	primaryValues, err := json.Marshal(doc.PrimaryValues())
+	if err != nil {
+		return err
+	}
+
+	changes := make(map[string]Change, len(old)+len(new))
+	for field, value := range old {
+		changes[field] = Change{Old: value}
+	}
+
+	for field, value := range new {
+		change := changes[field]
+		change.New = value
+		changes[field] = change
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	mutates := map[string]rel.Mutate{
+		"table_name":     rel.Set("table_name", doc.Table()),
+		"primary_values": rel.Set("primary_values", string(primaryValues)),
+		"action":         rel.Set("action", action),
+		"actor":          rel.Set("actor", Actor(ctx)),
+		"changes":        rel.Set("changes", string(data)),
+		"created_at":     rel.Set("created_at", r.Now()),
+	}
+
+	_, err = r.Adapter(ctx).Insert(ctx, rel.From(r.table), "id", mutates, rel.OnConflict{})
+	return err
+}
+
+// diff returns old and new values of fields that are changed.
+func diff(old map[string]interface{}, new map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	var (
+		oldChanged = make(map[string]interface{})
+		newChanged = make(map[string]interface{})
+	)
+
+	for field, value := range new {
+		if !reflect.DeepEqual(old[field], value) {
+			oldChanged[field] = old[field]
+			newChanged[field] = value
+		}
+	}
+
+	return oldChanged, newChanged
+}
+
+func values(doc *rel.Document) map[string]interface{} {
+	result := make(map[string]interface{}, len(doc.Fields()))
+	for _, field := range doc.Fields() {
+		result[field], _ = doc.Value(field)
+	}
+
+	return result
+}
+
+// New repository that writes audit log of the given record types to default audit_logs table.
+func New(repo rel.Repository, records ...interface{}) *Repository {
+	r := &Repository{
+		Repository: repo,
+		table:      auditTable,
+		types:      make(map[reflect.Type]bool),
+	}
+
+	r.Enable(records...)
+	return r
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,384 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/internal/testadapter"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func clock() time.Time {
+	return now
+}
+
+func newRepository(adapter rel.Adapter, records ...interface{}) *Repository {
+	return New(rel.New(adapter, rel.WithClock(clock)), records...)
+}
+
+type User struct {
+	ID   int
+	Name string
+	Age  int
+}
+
+type Tag struct {
+	ID   int
+	Name string
+}
+
+type Account struct {
+	ID      int
+	Name    string
+	Deleted bool
+}
+
+func auditMutates(action string, primaryValues string, changes string) map[string]rel.Mutate {
+	return tableAuditMutates("users", action, primaryValues, changes)
+}
+
+func tableAuditMutates(table string, action string, primaryValues string, changes string) map[string]rel.Mutate {
+	return map[string]rel.Mutate{
+		"table_name":     rel.Set("table_name", table),
+		"primary_values": rel.Set("primary_values", primaryValues),
+		"action":         rel.Set("action", action),
+		"actor":          rel.Set("actor", "admin"),
+		"changes":        rel.Set("changes", changes),
+		"created_at":     rel.Set("created_at", now),
+	}
+}
+
+func TestRepository_Insert(t *testing.T) {
+	var (
+		ctx     = WithActor(context.TODO(), "admin")
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		user    = User{Name: "Alice", Age: 20}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", rel.From("users"), mock.Anything, rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Insert", rel.From("audit_logs"), auditMutates(Insert, "[1]",
+		`{"age":{"old":null,"new":20},"id":{"old":null,"new":1},"name":{"old":null,"new":"Alice"}}`,
+	), rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	repo.MustInsert(ctx, &user)
+	assert.Equal(t, 1, user.ID)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_notEnabled(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		tag     = Tag{Name: "go"}
+	)
+
+	adapter.On("Insert", rel.From("tags"), mock.Anything, rel.OnConflict{}).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Insert(ctx, &tag))
+	assert.Nil(t, repo.Insert(ctx, nil))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Insert_error(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		user    = User{Name: "Alice"}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", rel.From("users"), mock.Anything, rel.OnConflict{}).Return(nil, errors.New("error")).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, errors.New("error"), repo.Insert(ctx, &user))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll(t *testing.T) {
+	var (
+		ctx     = WithActor(context.TODO(), "admin")
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter)
+		users   = []User{{Name: "Alice"}, {Name: "Bob"}}
+	)
+
+	repo.Enable(&User{})
+	repo.Table("audits")
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("InsertAll", rel.From("users"), mock.Anything, mock.Anything, rel.OnConflict{}).Return([]interface{}{1, 2}, nil).Once()
+	adapter.On("Insert", rel.From("audits"), auditMutates(Insert, "[1]",
+		`{"age":{"old":null,"new":0},"id":{"old":null,"new":1},"name":{"old":null,"new":"Alice"}}`,
+	), rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Insert", rel.From("audits"), auditMutates(Insert, "[2]",
+		`{"age":{"old":null,"new":0},"id":{"old":null,"new":2},"name":{"old":null,"new":"Bob"}}`,
+	), rel.OnConflict{}).Return(2, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	repo.MustInsertAll(ctx, &users)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_pointerElements(t *testing.T) {
+	var (
+		ctx     = WithActor(context.TODO(), "admin")
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		users   = []*User{{Name: "Alice"}}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("InsertAll", rel.From("users"), mock.Anything, mock.Anything, rel.OnConflict{}).Return([]interface{}{1}, nil).Once()
+	adapter.On("Insert", rel.From("audit_logs"), auditMutates(Insert, "[1]",
+		`{"age":{"old":null,"new":0},"id":{"old":null,"new":1},"name":{"old":null,"new":"Alice"}}`,
+	), rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.InsertAll(ctx, &users))
+	assert.Equal(t, 1, users[0].ID)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_InsertAll_notEnabled(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		tags    = []Tag{{Name: "go"}}
+	)
+
+	adapter.On("InsertAll", rel.From("tags"), mock.Anything, mock.Anything, rel.OnConflict{}).Return([]interface{}{1}, nil).Once()
+
+	assert.Nil(t, repo.InsertAll(ctx, &tags))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update(t *testing.T) {
+	var (
+		ctx     = WithActor(context.TODO(), "admin")
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		user    = User{ID: 1, Name: "Alice", Age: 20}
+		cursor  = &testadapter.Cursor{
+			Columns: []string{"id", "name", "age"},
+			Rows:    [][]interface{}{{int64(1), "Alice", int64(19)}},
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", rel.From("users").Where(where.Eq("id", 1)).Unscoped().Limit(1)).Return(cursor, nil).Once()
+	adapter.On("Update", rel.From("users").Where(where.Eq("id", 1)), "id", mock.Anything).Return(1, nil).Once()
+	adapter.On("Insert", rel.From("audit_logs"), auditMutates(Update, "[1]",
+		`{"age":{"old":19,"new":20},"name":{"old":"Alice","new":"Bob"}}`,
+	), rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	repo.MustUpdate(ctx, &user, rel.Set("name", "Bob"))
+	assert.Equal(t, "Bob", user.Name)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_notFound(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		user    = User{ID: 1, Name: "Alice"}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", rel.From("users").Where(where.Eq("id", 1)).Unscoped().Limit(1)).Return(&testadapter.Cursor{Columns: []string{"id"}}, nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, rel.NotFoundError{}, repo.Update(ctx, &user))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Update_notEnabled(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		tag     = Tag{ID: 1, Name: "go"}
+	)
+
+	adapter.On("Update", rel.From("tags").Where(where.Eq("id", 1)), "id", mock.Anything).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Update(ctx, &tag, rel.Set("name", "golang")))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete(t *testing.T) {
+	var (
+		ctx     = WithActor(context.TODO(), "admin")
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		user    = User{ID: 1, Name: "Alice", Age: 20}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", rel.From("users").Where(where.Eq("id", 1))).Return(1, nil).Once()
+	adapter.On("Insert", rel.From("audit_logs"), auditMutates(Delete, "[1]",
+		`{"age":{"old":20,"new":null},"id":{"old":1,"new":null},"name":{"old":"Alice","new":null}}`,
+	), rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	repo.MustDelete(ctx, &user)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_notEnabled(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		tag     = Tag{ID: 1}
+	)
+
+	adapter.On("Delete", rel.From("tags").Where(where.Eq("id", 1))).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Delete(ctx, &tag))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Restore(t *testing.T) {
+	var (
+		ctx     = WithActor(context.TODO(), "admin")
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, Account{})
+		account = Account{ID: 1, Name: "Alice", Deleted: true}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", rel.From("accounts").Where(where.Eq("id", 1)).Unscoped(), "", map[string]rel.Mutate{
+		"deleted": rel.Set("deleted", false),
+	}).Return(1, nil).Once()
+	adapter.On("Insert", rel.From("audit_logs"), tableAuditMutates("accounts", Restore, "[1]",
+		`{"deleted":{"old":true,"new":false}}`,
+	), rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	repo.MustRestore(ctx, &account)
+	assert.False(t, account.Deleted)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Restore_notFound(t *testing.T) {
+	var (
+		ctx     = WithActor(context.TODO(), "admin")
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, Account{})
+		account = Account{ID: 1, Deleted: true}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", rel.From("accounts").Where(where.Eq("id", 1)).Unscoped(), "", mock.Anything).Return(0, nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, rel.NotFoundError{}, repo.Restore(ctx, &account))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Restore_notEnabled(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		account = Account{ID: 1, Deleted: true}
+	)
+
+	adapter.On("Update", rel.From("accounts").Where(where.Eq("id", 1)).Unscoped(), "", mock.Anything).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Restore(ctx, &account))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_RestoreAny(t *testing.T) {
+	var (
+		ctx      = WithActor(context.TODO(), "admin")
+		adapter  = &testadapter.Adapter{}
+		repo     = newRepository(adapter, Account{})
+		accounts []Account
+		cursor   = &testadapter.Cursor{
+			Columns: []string{"id", "name", "deleted"},
+			Rows:    [][]interface{}{{int64(1), "Alice", true}, {int64(2), "Bob", false}},
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", rel.From("accounts").Where(where.Eq("name", "Alice")).Unscoped()).Return(cursor, nil).Once()
+	adapter.On("Update", rel.From("accounts").Where(where.Eq("name", "Alice")).Unscoped(), "", mock.Anything).Return(2, nil).Once()
+	adapter.On("Insert", rel.From("audit_logs"), tableAuditMutates("accounts", Restore, "[1]",
+		`{"deleted":{"old":true,"new":false}}`,
+	), rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Equal(t, 2, repo.MustRestoreAny(ctx, &accounts, where.Eq("name", "Alice")))
+	assert.Empty(t, accounts)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_RestoreAny_error(t *testing.T) {
+	var (
+		ctx      = WithActor(context.TODO(), "admin")
+		adapter  = &testadapter.Adapter{}
+		repo     = newRepository(adapter, Account{})
+		accounts []Account
+		err      = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", rel.From("accounts").Unscoped()).Return(&testadapter.Cursor{}, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	count, rerr := repo.RestoreAny(ctx, &accounts)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, err, rerr)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_RestoreAny_notEnabled(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = newRepository(adapter, User{})
+		records []Account
+	)
+
+	adapter.On("Update", rel.From("accounts").Unscoped(), "", mock.Anything).Return(1, nil).Once()
+
+	count, err := repo.RestoreAny(ctx, &records)
+	assert.Equal(t, 1, count)
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Schema(t *testing.T) {
+	var (
+		schema rel.Schema
+		repo   = New(nil)
+	)
+
+	repo.Schema(&schema)
+
+	assert.Len(t, schema.Migrations, 2)
+	assert.Equal(t, "audit_logs", schema.Migrations[0].(rel.Table).Name)
+	assert.Equal(t, "audit_logs_record", schema.Migrations[1].(rel.Index).Name)
+}
+
+func TestActor(t *testing.T) {
+	assert.Equal(t, "", Actor(context.TODO()))
+	assert.Equal(t, "admin", Actor(WithActor(context.TODO(), "admin")))
+}
@@ -12,10 +12,24 @@ type pair = [2]interface{}
 // This allows REL to efficiently to perform update operation only on updated fields and association.
 // The catch is, enabling changeset will duplicates the original struct values which consumes more memory.
 type Changeset struct {
-	doc       *Document
-	snapshot  []interface{}
-	assoc     map[string]Changeset
-	assocMany map[string]map[interface{}]Changeset
+	doc          *Document
+	snapshot     []interface{}
+	assoc        map[string]Changeset
+	assocMany    map[string]map[interface{}]Changeset
+	assocManyIDs map[string][]interface{}
+}
+
+// ChangeReport of a changeset, contains old and new values of changed fields and nested reports of associations.
+// Has many report contains changed, inserted and deleted records, deleted records are reported last.
+type ChangeReport struct {
+	Fields    map[string][2]interface{}
+	Assoc     map[string]ChangeReport
+	AssocMany map[string][]ChangeReport
+}
+
+// IsEmpty returns true if there's no change.
+func (cr ChangeReport) IsEmpty() bool {
+	return len(cr.Fields) == 0 && len(cr.Assoc) == 0 && len(cr.AssocMany) == 0
 }
 
 func (c Changeset) valueChanged(typ reflect.Type, old interface{}, new interface{}) bool {
@@ -52,15 +66,46 @@ func (c Changeset) FieldChanged(field string) bool {
 	return false
 }
 
-// Changes returns map of changes.
-func (c Changeset) Changes() map[string]interface{} {
+// Changes returns old and new values of changed fields.
+// Changes of associations are available using Report.
+func (c Changeset) Changes() map[string][2]interface{} {
+	return buildFieldChanges(c.doc, c)
+}
+
+// Report returns changes of fields and associations.
+func (c Changeset) Report() ChangeReport {
 	return buildChanges(c.doc, c)
 }
 
+// Changed returns true if any field or association is changed.
+func (c Changeset) Changed() bool {
+	return !c.Report().IsEmpty()
+}
+
+// ChangedFields returns changed fields in the order of struct fields.
+func (c Changeset) ChangedFields() []string {
+	var (
+		fields []string
+	)
+
+	for _, field := range c.doc.Fields() {
+		if c.FieldChanged(field) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+// Revert restores record and its loaded associations to the snapshot.
+func (c Changeset) Revert() {
+	c.revert(c.doc)
+}
+
 // Apply mutation.
 func (c Changeset) Apply(doc *Document, mut *Mutation) {
 	var (
-		t = Now()
+		t = mut.now()
 	)
 
 	for i, field := range c.doc.Fields() {
@@ -75,8 +120,8 @@ func (c Changeset) Apply(doc *Document, mut *Mutation) {
 		}
 	}
 
-	if !mut.IsMutatesEmpty() && c.doc.Flag(HasUpdatedAt) && c.doc.SetValue("updated_at", t) {
-		mut.Add(Set("updated_at", t))
+	if !mut.IsMutatesEmpty() && c.doc.Flag(HasUpdatedAt) && c.doc.SetValue(c.doc.data.updatedAt, t) {
+		mut.Add(Set(c.doc.data.updatedAt, t))
 	}
 
 	if mut.Cascade {
@@ -103,11 +148,11 @@ func (c Changeset) applyAssoc(field string, mut *Mutation) {
 	doc, _ := assoc.Document()
 
 	if ch, ok := c.assoc[field]; ok {
-		if amod := Apply(doc, ch); !amod.IsEmpty() {
+		if amod := applyMutators(doc, mut.clock, true, true, ch); !amod.IsEmpty() {
 			mut.SetAssoc(field, amod)
 		}
 	} else {
-		amod := Apply(doc, newStructset(doc, false))
+		amod := applyMutators(doc, mut.clock, true, true, newStructset(doc, false))
 		mut.SetAssoc(field, amod)
 	}
 }
@@ -131,11 +176,11 @@ func (c Changeset) applyAssocMany(field string, mut *Mutation) {
 			if ch, ok := chs[pValue]; ok {
 				updatedIDs[pValue] = struct{}{}
 
-				if amod := Apply(doc, ch); !amod.IsEmpty() {
+				if amod := applyMutators(doc, mut.clock, true, true, ch); !amod.IsEmpty() {
 					muts = append(muts, amod)
 				}
 			} else {
-				muts = append(muts, Apply(doc, newStructset(doc, false)))
+				muts = append(muts, applyMutators(doc, mut.clock, true, true, newStructset(doc, false)))
 			}
 		}
 
@@ -164,10 +209,11 @@ func NewChangeset(record interface{}) Changeset {
 
 func newChangeset(doc *Document) Changeset {
 	c := Changeset{
-		doc:       doc,
-		snapshot:  make([]interface{}, len(doc.Fields())),
-		assoc:     make(map[string]Changeset),
-		assocMany: make(map[string]map[interface{}]Changeset),
+		doc:          doc,
+		snapshot:     make([]interface{}, len(doc.Fields())),
+		assoc:        make(map[string]Changeset),
+		assocMany:    make(map[string]map[interface{}]Changeset),
+		assocManyIDs: make(map[string][]interface{}),
 	}
 
 	for i, field := range doc.Fields() {
@@ -183,7 +229,7 @@ func newChangeset(doc *Document) Changeset {
 	}
 
 	for _, field := range doc.HasMany() {
-		initChangesetAssocMany(doc, c.assocMany, field)
+		initChangesetAssocMany(doc, c.assocMany, c.assocManyIDs, field)
 	}
 
 	return c
@@ -198,7 +244,7 @@ func initChangesetAssoc(doc *Document, assoc map[string]Changeset, field string)
 	assoc[field] = newChangeset(doc)
 }
 
-func initChangesetAssocMany(doc *Document, assoc map[string]map[interface{}]Changeset, field string) {
+func initChangesetAssocMany(doc *Document, assoc map[string]map[interface{}]Changeset, ids map[string][]interface{}, field string) {
 	col, loaded := doc.Association(field).Collection()
 	if !loaded {
 		return
@@ -214,13 +260,38 @@ func initChangesetAssocMany(doc *Document, assoc map[string]map[interface{}]Chan
 
 		if !isZero(pValue) {
 			assoc[field][pValue] = newChangeset(doc)
+			ids[field] = append(ids[field], pValue)
 		}
 	}
 }
 
-func buildChanges(doc *Document, c Changeset) map[string]interface{} {
+func buildChanges(doc *Document, c Changeset) ChangeReport {
+	report := ChangeReport{
+		Fields: buildFieldChanges(doc, c),
+	}
+
+	if doc == nil || len(c.snapshot) == 0 {
+		return report
+	}
+
+	for _, field := range doc.BelongsTo() {
+		buildChangesAssoc(&report, c, field)
+	}
+
+	for _, field := range doc.HasOne() {
+		buildChangesAssoc(&report, c, field)
+	}
+
+	for _, field := range doc.HasMany() {
+		buildChangesAssocMany(&report, c, field)
+	}
+
+	return report
+}
+
+func buildFieldChanges(doc *Document, c Changeset) map[string]pair {
 	var (
-		changes = make(map[string]interface{})
+		changes = make(map[string]pair)
 		fields  []string
 	)
 
@@ -249,40 +320,28 @@ func buildChanges(doc *Document, c Changeset) map[string]interface{} {
 		}
 	}
 
-	if doc == nil || len(c.snapshot) == 0 {
-		return changes
-	}
-
-	for _, field := range doc.BelongsTo() {
-		buildChangesAssoc(changes, c, field)
-	}
-
-	for _, field := range doc.HasOne() {
-		buildChangesAssoc(changes, c, field)
-	}
-
-	for _, field := range doc.HasMany() {
-		buildChangesAssocMany(changes, c, field)
-	}
-
 	return changes
 }
 
-func buildChangesAssoc(out map[string]interface{}, c Changeset, field string) {
+func buildChangesAssoc(out *ChangeReport, c Changeset, field string) {
 	assoc := c.doc.Association(field)
 	if assoc.IsZero() {
 		return
 	}
 
 	doc, _ := assoc.Document()
-	if changes := buildChanges(doc, c.assoc[field]); len(changes) != 0 {
-		out[field] = changes
+	if report := buildChanges(doc, c.assoc[field]); !report.IsEmpty() {
+		if out.Assoc == nil {
+			out.Assoc = make(map[string]ChangeReport)
+		}
+
+		out.Assoc[field] = report
 	}
 }
 
-func buildChangesAssocMany(out map[string]interface{}, c Changeset, field string) {
+func buildChangesAssocMany(out *ChangeReport, c Changeset, field string) {
 	var (
-		changes    []map[string]interface{}
+		reports    []ChangeReport
 		chs        = c.assocMany[field]
 		assoc      = c.doc.Association(field)
 		col, _     = assoc.Collection()
@@ -300,21 +359,46 @@ func buildChangesAssocMany(out map[string]interface{}, c Changeset, field string
 			updatedIDs[pValue] = struct{}{}
 		}
 
-		if dChanges := buildChanges(doc, ch); len(dChanges) != 0 {
-			changes = append(changes, dChanges)
+		if report := buildChanges(doc, ch); !report.IsEmpty() {
+			reports = append(reports, report)
 		}
 	}
 
 	// leftover snapshot.
 	if len(updatedIDs) != len(chs) {
-		for id, ch := range chs {
+		for _, id := range c.assocManyIDs[field] {
 			if _, ok := updatedIDs[id]; !ok {
-				changes = append(changes, buildChanges(nil, ch))
+				reports = append(reports, buildChanges(nil, chs[id]))
 			}
 		}
 	}
 
-	if len(changes) != 0 {
-		out[field] = changes
+	if len(reports) != 0 {
+		if out.AssocMany == nil {
+			out.AssocMany = make(map[string][]ChangeReport)
+		}
+
+		out.AssocMany[field] = reports
+	}
+}
+
+// revert values of doc to snapshot, has many association is rebuilt using snapshot of its persisted records.
+func (c Changeset) revert(doc *Document) {
+	for i, field := range c.doc.Fields() {
+		doc.SetValue(field, c.snapshot[i])
+	}
+
+	for field, ch := range c.assoc {
+		assocDoc, _ := doc.Association(field).Document()
+		ch.revert(assocDoc)
+	}
+
+	for field, chs := range c.assocMany {
+		col, _ := doc.Association(field).Collection()
+		col.Reset()
+
+		for _, id := range c.assocManyIDs[field] {
+			chs[id].revert(col.Add())
+		}
 	}
 }
@@ -136,7 +136,7 @@ func TestChangeset(t *testing.T) {
 		user.Age = 21
 
 		assert.Equal(t, snapshot, changeset.snapshot)
-		assert.Equal(t, map[string]interface{}{
+		assert.Equal(t, map[string]pair{
 			"name": pair{"User 1", "User 2"},
 			"age":  pair{20, 21},
 		}, changeset.Changes())
@@ -161,6 +161,31 @@ func TestChangeset(t *testing.T) {
 	})
 }
 
+func TestChangeset_taggedUpdatedAt(t *testing.T) {
+	type tmp struct {
+		ID       int
+		Name     string
+		Modified time.Time `db:"modified,updated"`
+	}
+
+	var (
+		record    = tmp{ID: 1, Name: "Luffy"}
+		doc       = NewDocument(&record)
+		changeset = NewChangeset(&record)
+	)
+
+	record.Name = "Zoro"
+
+	assert.Equal(t, Mutation{
+		Cascade: true,
+		Mutates: map[string]Mutate{
+			"name":     Set("name", "Zoro"),
+			"modified": Set("modified", Now()),
+		},
+	}, Apply(doc, changeset))
+	assert.Equal(t, Now(), record.Modified)
+}
+
 func TestChangeset_byte_slice(t *testing.T) {
 	var (
 		ts   = time.Now()
@@ -192,7 +217,7 @@ func TestChangeset_byte_slice(t *testing.T) {
 		user.Metadata = []byte("{}")
 
 		assert.Equal(t, snapshot, changeset.snapshot)
-		assert.Equal(t, map[string]interface{}{
+		assert.Equal(t, map[string]pair{
 			"password": pair{[]byte("foo"), []byte("bar")},
 			"metadata": pair{json.RawMessage(`{"baz":"foo"}`), json.RawMessage("{}")},
 		}, changeset.Changes())
@@ -244,7 +269,7 @@ func TestChangeset_ptr(t *testing.T) {
 		address.UserID = &userID
 
 		assert.Equal(t, snapshot, changeset.snapshot)
-		assert.Equal(t, map[string]interface{}{
+		assert.Equal(t, map[string]pair{
 			"user_id": pair{2, 3},
 		}, changeset.Changes())
 	})
@@ -287,11 +312,16 @@ func TestChangeset_belongsTo(t *testing.T) {
 		address.User.Name = "User Satu"
 
 		assert.Equal(t, snapshot, changeset.assoc["user"].snapshot)
-		assert.Equal(t, map[string]interface{}{
-			"user": map[string]interface{}{
-				"name": pair{"User 1", "User Satu"},
+		assert.Equal(t, ChangeReport{
+			Fields: map[string]pair{},
+			Assoc: map[string]ChangeReport{
+				"user": {
+					Fields: map[string]pair{
+						"name": pair{"User 1", "User Satu"},
+					},
+				},
 			},
-		}, changeset.Changes())
+		}, changeset.Report())
 	})
 
 	t.Run("apply changeset", func(t *testing.T) {
@@ -341,15 +371,20 @@ func TestChangeset_belongsTo_new(t *testing.T) {
 		}
 
 		assert.Nil(t, changeset.assoc["user"].snapshot)
-		assert.Equal(t, map[string]interface{}{
-			"user": map[string]interface{}{
-				"id":         pair{nil, 0},
-				"name":       pair{nil, "User Satu"},
-				"age":        pair{nil, 20},
-				"created_at": pair{nil, time.Time{}},
-				"updated_at": pair{nil, time.Time{}},
+		assert.Equal(t, ChangeReport{
+			Fields: map[string]pair{},
+			Assoc: map[string]ChangeReport{
+				"user": {
+					Fields: map[string]pair{
+						"id":         pair{nil, 0},
+						"name":       pair{nil, "User Satu"},
+						"age":        pair{nil, 20},
+						"created_at": pair{nil, time.Time{}},
+						"updated_at": pair{nil, time.Time{}},
+					},
+				},
 			},
-		}, changeset.Changes())
+		}, changeset.Report())
 	})
 
 	t.Run("apply changeset", func(t *testing.T) {
@@ -406,13 +441,18 @@ func TestChangeset_hasOne(t *testing.T) {
 		user.Address.Notes = Notes("Home")
 
 		assert.Equal(t, snapshot, changeset.assoc["address"].snapshot)
-		assert.Equal(t, map[string]interface{}{
-			"address": map[string]interface{}{
-				"user_id": pair{nil, user.ID},
-				"street":  pair{"Grove Street", "Grove Street Blvd"},
-				"notes":   pair{Notes("HQ"), Notes("Home")},
+		assert.Equal(t, ChangeReport{
+			Fields: map[string]pair{},
+			Assoc: map[string]ChangeReport{
+				"address": {
+					Fields: map[string]pair{
+						"user_id": pair{nil, user.ID},
+						"street":  pair{"Grove Street", "Grove Street Blvd"},
+						"notes":   pair{Notes("HQ"), Notes("Home")},
+					},
+				},
 			},
-		}, changeset.Changes())
+		}, changeset.Report())
 	})
 
 	t.Run("apply changeset", func(t *testing.T) {
@@ -464,14 +504,19 @@ func TestChangeset_hasOne_new(t *testing.T) {
 		}
 
 		assert.Nil(t, changeset.assoc["address"].snapshot)
-		assert.Equal(t, map[string]interface{}{
-			"address": map[string]interface{}{
-				"id":      pair{nil, 0},
-				"user_id": pair{nil, user.ID},
-				"street":  pair{nil, "Grove Street Blvd"},
-				"notes":   pair{nil, Notes("Home")},
+		assert.Equal(t, ChangeReport{
+			Fields: map[string]pair{},
+			Assoc: map[string]ChangeReport{
+				"address": {
+					Fields: map[string]pair{
+						"id":      pair{nil, 0},
+						"user_id": pair{nil, user.ID},
+						"street":  pair{nil, "Grove Street Blvd"},
+						"notes":   pair{nil, Notes("Home")},
+					},
+				},
 			},
-		}, changeset.Changes())
+		}, changeset.Report())
 	})
 
 	t.Run("apply changeset", func(t *testing.T) {
@@ -532,27 +577,36 @@ func TestChangeset_hasMany(t *testing.T) {
 		user.Transactions[0].Status = "paid"
 		user.Transactions[1] = Transaction{Item: "Paper", Status: "pending"}
 
-		assert.Equal(t, map[string]interface{}{
-			"transactions": []map[string]interface{}{
-				{
-					"status": pair{Status("pending"), Status("paid")},
-				},
-				{
-					"id":         pair{nil, 0},
-					"item":       pair{nil, "Paper"},
-					"status":     pair{nil, Status("pending")},
-					"user_id":    pair{nil, 0},
-					"address_id": pair{nil, 0},
-				},
-				{
-					"id":         pair{12, nil},
-					"item":       pair{"Eraser", nil},
-					"status":     pair{Status("pending"), nil},
-					"user_id":    pair{0, nil},
-					"address_id": pair{0, nil},
+		assert.Equal(t, ChangeReport{
+			Fields: map[string]pair{},
+			AssocMany: map[string][]ChangeReport{
+				"transactions": {
+					{
+						Fields: map[string]pair{
+							"status": pair{Status("pending"), Status("paid")},
+						},
+					},
+					{
+						Fields: map[string]pair{
+							"id":         pair{nil, 0},
+							"item":       pair{nil, "Paper"},
+							"status":     pair{nil, Status("pending")},
+							"user_id":    pair{nil, 0},
+							"address_id": pair{nil, 0},
+						},
+					},
+					{
+						Fields: map[string]pair{
+							"id":         pair{12, nil},
+							"item":       pair{"Eraser", nil},
+							"status":     pair{Status("pending"), nil},
+							"user_id":    pair{0, nil},
+							"address_id": pair{0, nil},
+						},
+					},
 				},
 			},
-		}, changeset.Changes())
+		}, changeset.Report())
 	})
 
 	t.Run("apply changeset", func(t *testing.T) {
@@ -583,3 +637,71 @@ func TestChangeset_hasMany(t *testing.T) {
 		}, Apply(doc, changeset))
 	})
 }
+
+func TestChangeset_Changed(t *testing.T) {
+	var (
+		user = User{
+			ID:   1,
+			Name: "User 1",
+			Age:  20,
+			Transactions: []Transaction{
+				{ID: 11, Item: "Book"},
+			},
+		}
+		changeset = NewChangeset(&user)
+	)
+
+	assert.False(t, changeset.Changed())
+	assert.Nil(t, changeset.ChangedFields())
+
+	user.Transactions[0].Item = "Paper"
+	assert.True(t, changeset.Changed())
+	assert.Nil(t, changeset.ChangedFields())
+
+	user.Age = 21
+	user.Name = "User 2"
+	assert.True(t, changeset.Changed())
+	assert.Equal(t, []string{"name", "age"}, changeset.ChangedFields())
+}
+
+func TestChangeset_Revert(t *testing.T) {
+	var (
+		userID = 1
+		user   = User{
+			ID:   1,
+			Name: "User 1",
+			Age:  20,
+			Address: Address{
+				ID:     2,
+				UserID: &userID,
+				Street: "Grove Street",
+			},
+			Transactions: []Transaction{
+				{ID: 11, Item: "Book", Status: "pending"},
+				{ID: 12, Item: "Eraser", Status: "pending"},
+			},
+		}
+		changeset = NewChangeset(&user)
+	)
+
+	user.Name = "User 2"
+	user.Address.Street = "Thousand Sunny"
+	user.Address.UserID = nil
+	user.Transactions[0].Status = "paid"
+	user.Transactions[1] = Transaction{Item: "Paper"}
+	user.Transactions = append(user.Transactions, Transaction{Item: "Pen"})
+
+	assert.True(t, changeset.Changed())
+
+	changeset.Revert()
+
+	assert.False(t, changeset.Changed())
+	assert.Equal(t, "User 1", user.Name)
+	assert.Equal(t, 20, user.Age)
+	assert.Equal(t, "Grove Street", user.Address.Street)
+	assert.Equal(t, &userID, user.Address.UserID)
+	assert.Equal(t, []Transaction{
+		{ID: 11, Item: "Book", Status: "pending"},
+		{ID: 12, Item: "Eraser", Status: "pending"},
+	}, user.Transactions)
+}
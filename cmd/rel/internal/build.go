@@ -0,0 +1,283 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// ExecBuild command.
+// builds standalone migration binary that can be run without go toolchain.
+func ExecBuild(ctx context.Context, args []string) error {
+	var (
+		fs         = flag.NewFlagSet(args[1], flag.ExitOnError)
+		resolveEnv = environmentFlags(fs)
+		module     = fs.String("module", getModule(), "Module of the main package")
+		output     = fs.String("o", "rel-migrate", "Output path of migration binary")
+	)
+
+	fs.Parse(args[2:])
+
+	env, err := resolveEnv()
+	if err != nil {
+		return err
+	}
+
+	if env.Adapter == "" || env.Driver == "" {
+		return fmt.Errorf("rel: missing required parameters:\n\tadapter: %s\n\tdriver: %s", env.Adapter, env.Driver)
+	}
+
+	source, err := renderRunner(env, *module, "")
+	if err != nil {
+		return err
+	}
+
+	if err := buildRunner(ctx, source, *output); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(stdout, "Built:", *output)
+	fmt.Fprintln(stdout, "Usage:", *output, "-dsn=<dsn> [migrate|rollback|plan|dump|load]")
+	return nil
+}
+
+// cachedRunner returns path to compiled runner, the runner is only compiled when it's not available in cache dir.
+// name is the command using the runner and dirs are directories of go packages used by the runner,
+// after a new runner is compiled, previous runners of the same command and project are removed from cache dir.
+func cachedRunner(ctx context.Context, name string, source []byte, dirs ...string) (string, error) {
+	key, err := runnerKey(ctx, source, dirs...)
+	if err != nil {
+		return "", err
+	}
+
+	project, err := projectKey(dirs)
+	if err != nil {
+		return "", err
+	}
+
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		prefix = filepath.Join(cacheDir, "rel-"+name+"-"+project+"-")
+		runner = prefix + key
+	)
+
+	if runtime.GOOS == "windows" {
+		runner += ".exe"
+	}
+
+	if _, err := os.Stat(runner); err == nil {
+		return runner, nil
+	}
+
+	if err := buildRunner(ctx, source, runner); err != nil {
+		return runner, err
+	}
+
+	removeStaleRunners(prefix, runner)
+	return runner, nil
+}
+
+// removeStaleRunners removes runners with the same prefix except the current runner.
+// runners that are still being built by another process are kept.
+func removeStaleRunners(prefix string, runner string) {
+	paths, _ := filepath.Glob(prefix + "*")
+	for _, path := range paths {
+		if path != runner && filepath.Ext(path) != ".tmp" {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// projectKey hashes working directory and dirs used by the runner, so runners of different projects don't evict each other.
+func projectKey(dirs []string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(wd))
+	for _, dir := range dirs {
+		hash.Write([]byte{0})
+		hash.Write([]byte(filepath.Clean(dir)))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+// buildRunner compiles runner source to output path.
+func buildRunner(ctx context.Context, source []byte, output string) error {
+	file, err := ioutil.TempFile(tempdir, "rel-*.go")
+	check(err)
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(source)
+	check(err)
+	check(file.Close())
+
+	// build to temporary path first, so interrupted build won't be cached.
+	tmpOutput := output + ".tmp"
+	defer os.Remove(tmpOutput)
+
+	cmd := exec.CommandContext(ctx, "go", "build", "-mod=mod", "-o", tmpOutput, file.Name())
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpOutput, output)
+}
+
+// runnerKey hashes runner source, go toolchain and build target, files inside dirs,
+// files of local packages imported by go files inside dirs, go.mod and go.sum.
+func runnerKey(ctx context.Context, source []byte, dirs ...string) (string, error) {
+	var (
+		hash  = sha256.New()
+		paths []string
+	)
+
+	hash.Write(source)
+
+	toolchain, err := goToolchain(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	hash.Write(toolchain)
+
+	packageDirs, err := localPackageDirs(ctx, dirs)
+	if err != nil {
+		return "", err
+	}
+
+	for _, dir := range append(dirs, packageDirs...) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return "", errors.New("rel: error accessing read migration directory: " + dir)
+		}
+
+		sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+		for _, f := range files {
+			if !f.IsDir() {
+				paths = append(paths, filepath.Join(dir, f.Name()))
+			}
+		}
+	}
+
+	paths = append(paths, gomod, filepath.Join(filepath.Dir(gomod), "go.sum"))
+
+	for _, path := range paths {
+		// go.mod and go.sum might not be exists when using GOPATH.
+		content, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+
+		hash.Write([]byte(path))
+		hash.Write(content)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:32], nil
+}
+
+// goToolchain returns go version and build target used to compile the runner.
+func goToolchain(ctx context.Context) ([]byte, error) {
+	version, err := exec.CommandContext(ctx, "go", "version").Output()
+	if err != nil {
+		return nil, errors.New("rel: error reading go version: " + err.Error())
+	}
+
+	target, err := exec.CommandContext(ctx, "go", "env", "GOOS", "GOARCH", "CGO_ENABLED", "GOFLAGS").Output()
+	if err != nil {
+		return nil, errors.New("rel: error reading go env: " + err.Error())
+	}
+
+	return append(version, target...), nil
+}
+
+// localPackageDirs returns directories of packages outside module cache that are imported by go files inside dirs,
+// which are packages of the main module and packages replaced by local path.
+func localPackageDirs(ctx context.Context, dirs []string) ([]string, error) {
+	var (
+		result []string
+		seen   = make(map[string]bool)
+	)
+
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		seen[abs] = true
+	}
+
+	for _, dir := range dirs {
+		if files, _ := filepath.Glob(filepath.Join(dir, "*.go")); len(files) == 0 {
+			continue
+		}
+
+		pkg := dir
+		if !filepath.IsAbs(pkg) {
+			pkg = "./" + filepath.ToSlash(filepath.Clean(pkg))
+		}
+
+		var (
+			stderr bytes.Buffer
+			cmd    = exec.CommandContext(ctx, "go", "list", "-mod=mod", "-deps", "-f", localPackageTemplate, pkg)
+		)
+
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, errors.New("rel: error listing imported packages: " + dir + ": " + strings.TrimSpace(stderr.String()))
+		}
+
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !seen[line] {
+				seen[line] = true
+				result = append(result, line)
+			}
+		}
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+const localPackageTemplate = `{{if not .Standard}}{{if not .Module}}{{.Dir}}{{else if .Module.Main}}{{.Dir}}{{else if .Module.Replace}}{{if not .Module.Replace.Version}}{{.Dir}}{{end}}{{end}}{{end}}`
+
+func getCacheDir() (string, error) {
+	dir := os.Getenv("REL_CACHE_DIR")
+	if dir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", errors.New("rel: error accessing cache directory, set REL_CACHE_DIR to override")
+		}
+
+		dir = filepath.Join(userCacheDir, "rel")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.New("rel: error creating cache directory: " + dir)
+	}
+
+	return dir, nil
+}
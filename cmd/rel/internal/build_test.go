@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecBuild(t *testing.T) {
+	t.Run("missing required parameters", func(t *testing.T) {
+		var (
+			ctx  = context.TODO()
+			args = []string{"rel", "build"}
+		)
+
+		assert.Equal(t, errors.New("rel: missing required parameters:\n\tadapter: \n\tdriver: "), ExecBuild(ctx, args))
+	})
+
+	t.Run("invalid migration dir", func(t *testing.T) {
+		var (
+			ctx  = context.TODO()
+			args = []string{
+				"rel",
+				"build",
+				"-adapter=github.com/go-rel/sqlite3",
+				"-driver=github.com/mattn/go-sqlite3",
+				"-dir=db",
+			}
+		)
+
+		assert.Equal(t, errors.New("rel: error accessing read migration directory: db"), ExecBuild(ctx, args))
+	})
+}
+
+func TestRunnerKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rel-migrations")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "1_create_users.up.sql")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("CREATE TABLE users;"), 0644))
+
+	key, err := runnerKey(context.TODO(), []byte("source"), dir)
+	assert.Nil(t, err)
+	assert.Len(t, key, 32)
+
+	sameKey, _ := runnerKey(context.TODO(), []byte("source"), dir)
+	assert.Equal(t, key, sameKey)
+
+	sourceKey, _ := runnerKey(context.TODO(), []byte("other source"), dir)
+	assert.NotEqual(t, key, sourceKey)
+
+	assert.Nil(t, ioutil.WriteFile(file, []byte("CREATE TABLE accounts;"), 0644))
+	contentKey, _ := runnerKey(context.TODO(), []byte("source"), dir)
+	assert.NotEqual(t, key, contentKey)
+
+	os.Setenv("GOARCH", "arm64")
+	targetKey, _ := runnerKey(context.TODO(), []byte("source"), dir)
+	os.Unsetenv("GOARCH")
+	assert.NotEqual(t, contentKey, targetKey)
+
+	_, err = runnerKey(context.TODO(), []byte("source"), "db")
+	assert.Equal(t, errors.New("rel: error accessing read migration directory: db"), err)
+}
+
+func TestRunnerKey_localPackage(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	defer os.Chdir(wd)
+
+	dir, err := ioutil.TempDir("", "rel-module")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		model     = filepath.Join(dir, "models", "user.go")
+		migration = filepath.Join(dir, "db", "migrations", "1_create_users.go")
+	)
+
+	assert.Nil(t, os.MkdirAll(filepath.Dir(model), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Dir(migration), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n\ngo 1.15\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(model, []byte("package models\n\nconst Table = \"users\"\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(migration, []byte("package migrations\n\nimport \"example/models\"\n\nvar table = models.Table\n"), 0644))
+	assert.Nil(t, os.Chdir(dir))
+
+	key, err := runnerKey(context.TODO(), []byte("source"), "db/migrations")
+	assert.Nil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(model, []byte("package models\n\nconst Table = \"accounts\"\n"), 0644))
+	modelKey, err := runnerKey(context.TODO(), []byte("source"), "db/migrations")
+	assert.Nil(t, err)
+	assert.NotEqual(t, key, modelKey)
+}
+
+func TestGetCacheDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rel-cache")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("REL_CACHE_DIR", filepath.Join(dir, "rel"))
+	defer os.Setenv("REL_CACHE_DIR", "")
+
+	cacheDir, err := getCacheDir()
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "rel"), cacheDir)
+	assert.DirExists(t, cacheDir)
+}
+
+func TestCachedRunner_cached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rel-cache")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("REL_CACHE_DIR", dir)
+	defer os.Setenv("REL_CACHE_DIR", "")
+
+	var (
+		source     = []byte("package main")
+		key, _     = runnerKey(context.TODO(), source, "testdata/migrations")
+		project, _ = projectKey([]string{"testdata/migrations"})
+		runner     = filepath.Join(dir, "rel-migrate-"+project+"-"+key)
+	)
+
+	assert.Nil(t, ioutil.WriteFile(runner, nil, 0755))
+
+	// should not trigger build.
+	path, err := cachedRunner(context.TODO(), "migrate", source, "testdata/migrations")
+	assert.Nil(t, err)
+	assert.Equal(t, runner, path)
+}
+
+func TestCachedRunner_removeStale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rel-cache")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	os.Setenv("REL_CACHE_DIR", dir)
+	defer os.Setenv("REL_CACHE_DIR", "")
+
+	var (
+		project, _ = projectKey([]string{"testdata/seeds"})
+		stale      = filepath.Join(dir, "rel-seed-"+project+"-stale")
+		building   = filepath.Join(dir, "rel-seed-"+project+"-other.tmp")
+		migrate    = filepath.Join(dir, "rel-migrate-"+project+"-stale")
+	)
+
+	for _, path := range []string{stale, building, migrate} {
+		assert.Nil(t, ioutil.WriteFile(path, nil, 0755))
+	}
+
+	runner, err := cachedRunner(context.TODO(), "seed", []byte("package main\n\nfunc main() {}\n"), "testdata/seeds")
+	assert.Nil(t, err)
+	assert.FileExists(t, runner)
+	assert.NoFileExists(t, stale)
+	assert.FileExists(t, building)
+	assert.FileExists(t, migrate)
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultDir          = "db/migrations"
+	defaultSeedDir      = "db/seeds"
+	defaultVersionTable = "rel_schema_versions"
+)
+
+var (
+	configFiles = []string{"rel.yaml", "rel.yml", "rel.toml"}
+)
+
+// environment configuration loaded from config file.
+type environment struct {
+	Adapter      string
+	Driver       string
+	DSN          string
+	Dir          string
+	SeedDir      string
+	VersionTable string
+}
+
+func newEnvironment(values map[string]string) environment {
+	return environment{
+		Adapter:      values["adapter"],
+		Driver:       values["driver"],
+		DSN:          os.ExpandEnv(values["dsn"]),
+		Dir:          values["dir"],
+		SeedDir:      values["seed_dir"],
+		VersionTable: values["version_table"],
+	}
+}
+
+// environmentFlags defines flags for database and migration configuration.
+// The returned function must be called after flags are parsed to resolve the environment.
+// Values are resolved in the following order: flags, environment variables, config file and defaults,
+// except when environment is selected explicitly using -env or REL_ENV, in which case the config file takes
+// precedence over database environment variables.
+// Adapter, driver and dsn from environment variables and config file are taken together from a single source,
+// use ${VAR} in dsn to read environment variables from config file.
+// Each of -adapter, -driver and -dsn flag overrides only its own value.
+// Environment that's not selected explicitly is optional, so the default development environment
+// doesn't have to exist in config file.
+func environmentFlags(fs *flag.FlagSet) func() (environment, error) {
+	var (
+		config       = fs.String("config", "", "Path to config file (default rel.yaml, rel.yml or rel.toml)")
+		env          = fs.String("env", getEnvironmentName(), "Environment name in config file, can be set using REL_ENV")
+		adapter      = fs.String("adapter", "", "Adapter package")
+		driver       = fs.String("driver", "", "Driver package")
+		dsn          = fs.String("dsn", "", "DSN for database connection")
+		dir          = fs.String("dir", "", "Path to directory containing migration files (default \""+defaultDir+"\")")
+		seedDir      = fs.String("seed-dir", "", "Path to directory containing seed files (default \""+defaultSeedDir+"\")")
+		versionTable = fs.String("version-table", "", "Table to store applied migration versions (default \""+defaultVersionTable+"\")")
+	)
+
+	return func() (environment, error) {
+		var (
+			envSet bool
+		)
+
+		fs.Visit(func(f *flag.Flag) {
+			envSet = envSet || f.Name == "env"
+		})
+
+		envSelected := envSet || os.Getenv("REL_ENV") != ""
+		result, found, err := loadEnvironment(*config, *env, envSelected)
+		if err != nil {
+			return result, err
+		}
+
+		if envAdapter, envDriver, envDSN := getDatabaseInfo(); envDSN != "" && !(found && envSelected) {
+			result.Adapter, result.Driver, result.DSN = envAdapter, envDriver, envDSN
+		}
+
+		result.Adapter = firstNonEmpty(*adapter, result.Adapter)
+		result.Driver = firstNonEmpty(*driver, result.Driver)
+		result.DSN = firstNonEmpty(*dsn, result.DSN)
+		result.Dir = firstNonEmpty(*dir, result.Dir, defaultDir)
+		result.SeedDir = firstNonEmpty(*seedDir, result.SeedDir, defaultSeedDir)
+		result.VersionTable = firstNonEmpty(*versionTable, result.VersionTable, defaultVersionTable)
+
+		return result, nil
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+func getEnvironmentName() string {
+	if env := os.Getenv("REL_ENV"); env != "" {
+		return env
+	}
+
+	return "development"
+}
+
+// loadEnvironment from config file.
+// when filename is empty, it'll look for rel.yaml, rel.yml or rel.toml in working directory.
+// It returns false when the config file or the environment doesn't exist, which is an error if required is true.
+func loadEnvironment(filename string, name string, required bool) (environment, bool, error) {
+	if filename == "" {
+		for _, f := range configFiles {
+			if _, err := os.Stat(f); err == nil {
+				filename = f
+				break
+			}
+		}
+
+		if filename == "" && required {
+			return environment{}, false, errors.New("rel: config file not found for environment: " + name)
+		} else if filename == "" {
+			return environment{}, false, nil
+		}
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return environment{}, false, errors.New("rel: error reading config file: " + filename)
+	}
+
+	var (
+		config map[string]map[string]string
+	)
+
+	if filepath.Ext(filename) == ".toml" {
+		_, err = toml.Decode(string(data), &config)
+	} else {
+		err = yaml.Unmarshal(data, &config)
+	}
+
+	if err != nil {
+		return environment{}, false, errors.New("rel: invalid config file: " + filename + ": " + err.Error())
+	}
+
+	values, ok := config[name]
+	if !ok && required {
+		return environment{}, false, errors.New("rel: environment not found in " + filename + ": " + name)
+	} else if !ok {
+		return environment{}, false, nil
+	}
+
+	return newEnvironment(values), true, nil
+}
@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadEnvironment(t *testing.T) {
+	os.Setenv("REL_TEST_PASSWORD", "secret")
+	defer os.Setenv("REL_TEST_PASSWORD", "")
+
+	for _, filename := range []string{"testdata/config/rel.yaml", "testdata/config/rel.toml"} {
+		t.Run(filename, func(t *testing.T) {
+			env, found, err := loadEnvironment(filename, "production", true)
+			assert.Nil(t, err)
+			assert.True(t, found)
+			assert.Equal(t, environment{
+				Adapter:      "github.com/go-rel/postgres",
+				Driver:       "github.com/lib/pq",
+				DSN:          "postgres://secret@localhost/app",
+				Dir:          "db/app/migrations",
+				SeedDir:      "db/app/seeds",
+				VersionTable: "app_schema_versions",
+			}, env)
+
+			_, found, err = loadEnvironment(filename, "staging", true)
+			assert.Equal(t, errors.New("rel: environment not found in "+filename+": staging"), err)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestLoadEnvironment_notExists(t *testing.T) {
+	env, found, err := loadEnvironment("", "development", false)
+	assert.Nil(t, err)
+	assert.False(t, found)
+	assert.Equal(t, environment{}, env)
+
+	_, _, err = loadEnvironment("testdata/config/rel.json", "development", false)
+	assert.Equal(t, errors.New("rel: error reading config file: testdata/config/rel.json"), err)
+}
+
+func TestLoadEnvironment_invalid(t *testing.T) {
+	_, _, err := loadEnvironment("testdata/migrations/3_create_tags.up.sql", "development", false)
+	assert.NotNil(t, err)
+}
+
+func TestLoadEnvironment_invalidTOML(t *testing.T) {
+	_, _, err := loadEnvironment("testdata/config/invalid.toml", "development", false)
+	assert.NotNil(t, err)
+}
+
+func TestLoadEnvironment_optional(t *testing.T) {
+	env, found, err := loadEnvironment("testdata/config/rel.yaml", "staging", false)
+	assert.Nil(t, err)
+	assert.False(t, found)
+	assert.Equal(t, environment{}, env)
+}
+
+func TestEnvironmentFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		env    map[string]string
+		result environment
+		err    error
+	}{
+		{
+			name: "defaults",
+			result: environment{
+				Dir:          "db/migrations",
+				SeedDir:      "db/seeds",
+				VersionTable: "rel_schema_versions",
+			},
+		},
+		{
+			name: "config file",
+			args: []string{"-config=testdata/config/rel.yaml"},
+			result: environment{
+				Adapter:      "github.com/go-rel/sqlite3",
+				Driver:       "github.com/mattn/go-sqlite3",
+				DSN:          "development.db",
+				Dir:          "db/migrations",
+				SeedDir:      "db/seeds",
+				VersionTable: "rel_schema_versions",
+			},
+		},
+		{
+			name: "config file with environment from env var",
+			args: []string{"-config=testdata/config/rel.toml"},
+			env:  map[string]string{"REL_ENV": "production"},
+			result: environment{
+				Adapter:      "github.com/go-rel/postgres",
+				Driver:       "github.com/lib/pq",
+				DSN:          "postgres://@localhost/app",
+				Dir:          "db/app/migrations",
+				SeedDir:      "db/app/seeds",
+				VersionTable: "app_schema_versions",
+			},
+		},
+		{
+			name: "env vars override config file",
+			args: []string{"-config=testdata/config/rel.yaml"},
+			env:  map[string]string{"SQLITE3_DATABASE": "test.db"},
+			result: environment{
+				Adapter:      "github.com/go-rel/sqlite3",
+				Driver:       "github.com/mattn/go-sqlite3",
+				DSN:          "test.db",
+				Dir:          "db/migrations",
+				SeedDir:      "db/seeds",
+				VersionTable: "rel_schema_versions",
+			},
+		},
+		{
+			name: "env vars don't mix with config file",
+			args: []string{"-config=testdata/config/rel.yaml"},
+			env:  map[string]string{"DATABASE_URL": "postgres://dev-from-dotenv"},
+			result: environment{
+				DSN:          "postgres://dev-from-dotenv",
+				Dir:          "db/migrations",
+				SeedDir:      "db/seeds",
+				VersionTable: "rel_schema_versions",
+			},
+		},
+		{
+			name: "selected environment overrides env vars",
+			args: []string{"-config=testdata/config/rel.yaml", "-env=production"},
+			env:  map[string]string{"DATABASE_URL": "postgres://dev-from-dotenv", "REL_TEST_PASSWORD": "secret"},
+			result: environment{
+				Adapter:      "github.com/go-rel/postgres",
+				Driver:       "github.com/lib/pq",
+				DSN:          "postgres://secret@localhost/app",
+				Dir:          "db/app/migrations",
+				SeedDir:      "db/app/seeds",
+				VersionTable: "app_schema_versions",
+			},
+		},
+		{
+			name: "environment from env var overrides env vars",
+			args: []string{"-config=testdata/config/rel.toml"},
+			env:  map[string]string{"REL_ENV": "production", "MYSQL_HOST": "localhost"},
+			result: environment{
+				Adapter:      "github.com/go-rel/postgres",
+				Driver:       "github.com/lib/pq",
+				DSN:          "postgres://@localhost/app",
+				Dir:          "db/app/migrations",
+				SeedDir:      "db/app/seeds",
+				VersionTable: "app_schema_versions",
+			},
+		},
+		{
+			name: "flags override env vars and config file",
+			args: []string{"-config=testdata/config/rel.yaml", "-env=production", "-dsn=flag.db", "-dir=db", "-seed-dir=seeds", "-version-table=versions"},
+			env:  map[string]string{"SQLITE3_DATABASE": "test.db"},
+			result: environment{
+				Adapter:      "github.com/go-rel/postgres",
+				Driver:       "github.com/lib/pq",
+				DSN:          "flag.db",
+				Dir:          "db",
+				SeedDir:      "seeds",
+				VersionTable: "versions",
+			},
+		},
+		{
+			name: "default environment not in config file",
+			args: []string{"-config=testdata/config/production.yaml", "-adapter=github.com/go-rel/sqlite3", "-driver=github.com/mattn/go-sqlite3", "-dsn=test.db"},
+			result: environment{
+				Adapter:      "github.com/go-rel/sqlite3",
+				Driver:       "github.com/mattn/go-sqlite3",
+				DSN:          "test.db",
+				Dir:          "db/migrations",
+				SeedDir:      "db/seeds",
+				VersionTable: "rel_schema_versions",
+			},
+		},
+		{
+			name: "selected environment not in config file",
+			args: []string{"-config=testdata/config/production.yaml"},
+			env:  map[string]string{"REL_ENV": "staging"},
+			err:  errors.New("rel: environment not found in testdata/config/production.yaml: staging"),
+		},
+		{
+			name: "environment without config file",
+			args: []string{"-env=production"},
+			err:  errors.New("rel: config file not found for environment: production"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for key, value := range test.env {
+				os.Setenv(key, value)
+				defer os.Setenv(key, "")
+			}
+
+			var (
+				fs      = flag.NewFlagSet("migrate", flag.ContinueOnError)
+				resolve = environmentFlags(fs)
+			)
+
+			assert.Nil(t, fs.Parse(test.args))
+
+			result, err := resolve()
+			assert.Equal(t, test.err, err)
+			if test.err == nil {
+				assert.Equal(t, test.result, result)
+			}
+		})
+	}
+}
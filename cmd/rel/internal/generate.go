@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/serenize/snaker"
+)
+
+const migrationFileTemplate = `package migrations
+
+import "github.com/go-rel/rel"
+
+// Migrate{{.Name}} definition
+func Migrate{{.Name}}(schema *rel.Schema) {
+{{- if .Up}}
+	{{.Up}}
+{{- end}}
+}
+{{- if .Down}}
+
+// Rollback{{.Name}} definition
+func Rollback{{.Name}}(schema *rel.Schema) {
+	{{.Down}}
+}
+{{- end}}
+`
+
+var (
+	now = time.Now
+)
+
+// ExecGenerate command.
+func ExecGenerate(ctx context.Context, args []string) error {
+	if len(args) < 3 || args[2] != "migration" {
+		return errors.New("rel: available generators are: migration")
+	}
+
+	var (
+		fs         = flag.NewFlagSet(args[1], flag.ExitOnError)
+		resolveEnv = environmentFlags(fs)
+	)
+
+	fs.Parse(args[3:])
+	if fs.NArg() == 0 {
+		return errors.New("rel: missing migration name")
+	}
+
+	name := fs.Arg(0)
+	// allows flags to be defined after migration name.
+	fs.Parse(fs.Args()[1:])
+
+	if !reMigrationName.MatchString(name) {
+		return errors.New("rel: invalid migration name: " + name)
+	}
+
+	env, err := resolveEnv()
+	if err != nil {
+		return err
+	}
+
+	dir := env.Dir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.New("rel: error creating migration directory: " + dir)
+	}
+
+	var (
+		up, down = migrationStatements(name)
+		filename = filepath.Join(dir, now().Format("20060102150405")+"_"+name+".go")
+		tmpl     = template.Must(template.New("migration").Parse(migrationFileTemplate))
+	)
+
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return errors.New("rel: error creating migration file: " + filename)
+	}
+
+	err = tmpl.Execute(file, struct {
+		Name string
+		Up   string
+		Down string
+	}{
+		Name: snaker.SnakeToCamel(name),
+		Up:   up,
+		Down: down,
+	})
+	check(err)
+	check(file.Close())
+
+	fmt.Fprintln(stdout, "Created:", filename)
+	return nil
+}
+
+// migrationStatements infers up and down schema statements from migration name.
+func migrationStatements(name string) (string, string) {
+	if result := reAddColumnMigration.FindStringSubmatch(name); len(result) == 3 {
+		return fmt.Sprintf("schema.AddColumn(%q, %q, rel.String)", result[2], result[1]),
+			fmt.Sprintf("schema.DropColumn(%q, %q)", result[2], result[1])
+	}
+
+	if result := reCreateTableMigration.FindStringSubmatch(name); len(result) == 2 {
+		return fmt.Sprintf("schema.CreateTable(%q, func(t *rel.Table) {\n\t\tt.ID(\"id\")\n\t})", result[1]),
+			fmt.Sprintf("schema.DropTable(%q)", result[1])
+	}
+
+	return "", ""
+}
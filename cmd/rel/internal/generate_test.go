@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/serenize/snaker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rel-migrations")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	now = func() time.Time { return time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC) }
+	stdout = &bytes.Buffer{}
+	defer func() {
+		now = time.Now
+		stdout = os.Stdout
+	}()
+
+	tests := []struct {
+		name     string
+		content  string
+		rollback bool
+	}{
+		{
+			name:     "create_users",
+			rollback: true,
+			content: `package migrations
+
+import "github.com/go-rel/rel"
+
+// MigrateCreateUsers definition
+func MigrateCreateUsers(schema *rel.Schema) {
+	schema.CreateTable("users", func(t *rel.Table) {
+		t.ID("id")
+	})
+}
+
+// RollbackCreateUsers definition
+func RollbackCreateUsers(schema *rel.Schema) {
+	schema.DropTable("users")
+}
+`,
+		},
+		{
+			name:     "add_email_to_users",
+			rollback: true,
+			content: `package migrations
+
+import "github.com/go-rel/rel"
+
+// MigrateAddEmailToUsers definition
+func MigrateAddEmailToUsers(schema *rel.Schema) {
+	schema.AddColumn("users", "email", rel.String)
+}
+
+// RollbackAddEmailToUsers definition
+func RollbackAddEmailToUsers(schema *rel.Schema) {
+	schema.DropColumn("users", "email")
+}
+`,
+		},
+		{
+			name: "backfill_users",
+			content: `package migrations
+
+import "github.com/go-rel/rel"
+
+// MigrateBackfillUsers definition
+func MigrateBackfillUsers(schema *rel.Schema) {
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				ctx  = context.TODO()
+				args = []string{"rel", "generate", "migration", test.name, "-dir=" + dir}
+				file = filepath.Join(dir, "20210203040506_"+test.name+".go")
+			)
+
+			assert.Nil(t, ExecGenerate(ctx, args))
+
+			content, err := ioutil.ReadFile(file)
+			assert.Nil(t, err)
+			assert.Equal(t, test.content, string(content))
+
+			migrations, err := scanMigration(dir)
+			assert.Nil(t, err)
+			assert.Contains(t, migrations, migration{Version: "20210203040506", Name: snaker.SnakeToCamel(test.name), Rollback: test.rollback})
+		})
+	}
+
+	t.Run("already exists", func(t *testing.T) {
+		var (
+			ctx  = context.TODO()
+			args = []string{"rel", "generate", "migration", "-dir=" + dir, "create_users"}
+			file = filepath.Join(dir, "20210203040506_create_users.go")
+		)
+
+		assert.Equal(t, errors.New("rel: error creating migration file: "+file), ExecGenerate(ctx, args))
+	})
+}
+
+func TestExecGenerate_environmentDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rel-migrations")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	now = func() time.Time { return time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC) }
+	stdout = &bytes.Buffer{}
+	defer func() {
+		now = time.Now
+		stdout = os.Stdout
+	}()
+
+	var (
+		config = filepath.Join(dir, "rel.yaml")
+		args   = []string{"rel", "generate", "migration", "-config=" + config, "-env=app", "create_users"}
+	)
+
+	assert.Nil(t, ioutil.WriteFile(config, []byte("app:\n  dir: "+filepath.Join(dir, "app")+"\n"), 0644))
+	assert.Nil(t, ExecGenerate(context.TODO(), args))
+	assert.FileExists(t, filepath.Join(dir, "app", "20210203040506_create_users.go"))
+}
+
+func TestExecGenerate_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  error
+	}{
+		{
+			name: "unknown generator",
+			args: []string{"rel", "generate", "model", "user"},
+			err:  errors.New("rel: available generators are: migration"),
+		},
+		{
+			name: "missing name",
+			args: []string{"rel", "generate", "migration"},
+			err:  errors.New("rel: missing migration name"),
+		},
+		{
+			name: "invalid name",
+			args: []string{"rel", "generate", "migration", "CreateUsers"},
+			err:  errors.New("rel: invalid migration name: CreateUsers"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.err, ExecGenerate(context.TODO(), test.args))
+		})
+	}
+}
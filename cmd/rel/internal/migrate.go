@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"flag"
@@ -9,8 +10,12 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"text/template"
 
+	"github.com/go-rel/rel/migrator"
 	"github.com/serenize/snaker"
 )
 
@@ -19,20 +24,31 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	_ "{{.Driver}}"
 	db "{{.Adapter}}"
 	"github.com/go-rel/rel"
-	"github.com/go-rel/migration"
+	migration "github.com/go-rel/rel/migrator"
+	{{- if .Package}}
 
 	"{{.Package}}"
+	{{- end}}
+	{{- if .Snapshot}}
+	snapshot "{{.Snapshot}}"
+	{{- end}}
 )
 
 var (
 	shutdowns []func() error
+	dsn       = flag.String("dsn", os.Getenv("DATABASE_URL"), "DSN for database connection")
+	verbose   = flag.Bool("verbose", false, "Show logs from REL")
+	schemaFile = flag.String("schema-file", "db/schema.sql", "Path to schema snapshot file")
 )
 
 func logger(ctx context.Context, op string, message string) func(err error) {
@@ -50,7 +66,7 @@ func logger(ctx context.Context, op string, message string) func(err error) {
 		duration := time.Since(t)
 		if op == "migrate" || op == "rollback" {
 			log.Print("=> Done: ", op, " ", message, " in ", duration)
-		} else if {{.Verbose}} {
+		} else if *verbose {
 			log.Print("\t[duration: ", duration, " op: ", op, "] ", message)
 		}
 
@@ -65,7 +81,10 @@ func main() {
 		ctx = context.Background()
 	)
 
-	adapter, err := db.Open("{{.DSN}}")
+	log.SetFlags(0)
+	flag.Parse()
+
+	adapter, err := db.Open(*dsn)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -75,15 +94,121 @@ func main() {
 		m    = migration.New(repo)
 	)
 
-	log.SetFlags(0)
 	repo.Instrumentation(logger)
 	m.Instrumentation(logger)
+	{{- if .VersionTable}}
+	m.VersionTable({{printf "%q" .VersionTable}})
+	{{- end}}
 
 	{{range .Migrations}}
+	{{- if .SQL}}
+	m.Register({{.Version}}, func(schema *rel.Schema) {
+		{{- if .UpNoTransaction}}
+		schema.NoTransaction()
+		{{- end}}
+		{{- range .Up}}
+		schema.Exec(rel.Raw({{printf "%q" .}}))
+		{{- end}}
+	}, func(schema *rel.Schema) {
+		{{- if .DownNoTransaction}}
+		schema.NoTransaction()
+		{{- end}}
+		{{- range .Down}}
+		schema.Exec(rel.Raw({{printf "%q" .}}))
+		{{- end}}
+	})
+	{{- else if .Rollback}}
 	m.Register({{.Version}}, migrations.Migrate{{.Name}}, migrations.Rollback{{.Name}})
+	{{- else}}
+	m.Register({{.Version}}, migrations.Migrate{{.Name}}, reverse(migrations.Migrate{{.Name}}))
+	{{- end}}
 	{{end}}
 
-	{{.Command}}
+	switch flag.Arg(0) {
+	case "rollback":
+		m.Rollback(ctx)
+	case "plan":
+		plans := m.Plan(ctx)
+		if len(plans) == 0 {
+			log.Print("No pending migration")
+		}
+
+		for _, plan := range plans {
+			log.Print(plan)
+		}
+	case "dump":
+		dump(ctx, repo, &m)
+	case "load":
+		load(ctx, &m)
+	default:
+		m.Migrate(ctx)
+	}
+}
+
+func reverse(up func(schema *rel.Schema)) func(schema *rel.Schema) {
+	return func(schema *rel.Schema) {
+		var upSchema rel.Schema
+		up(&upSchema)
+
+		downSchema, err := upSchema.Reverse()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		schema.Migrations = append(schema.Migrations, downSchema.Migrations...)
+	}
+}
+
+func dump(ctx context.Context, repo rel.Repository, m *migration.Migrator) {
+	var (
+		s   = m.Dump(ctx)
+		err error
+	)
+
+	if err = os.MkdirAll(filepath.Dir(*schemaFile), 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Create(*schemaFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if filepath.Ext(*schemaFile) == ".go" {
+		err = s.WriteGo(file, filepath.Base(filepath.Dir(*schemaFile)))
+	} else if builder, ok := repo.Adapter(ctx).(rel.MigrationBuilder); ok {
+		err = s.WriteSQL(file, builder)
+	} else {
+		log.Fatal("rel: adapter does not support sql schema dump")
+	}
+
+	if err == nil {
+		err = file.Close()
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Print("Dumped: ", *schemaFile, " version ", s.Version)
+}
+
+func load(ctx context.Context, m *migration.Migrator) {
+	{{- if .Snapshot}}
+	s := migration.NewSnapshot(snapshot.Version, snapshot.Schema)
+	{{- else}}
+	file, err := os.Open(*schemaFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := migration.ReadSnapshot(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	{{- end}}
+
+	m.Load(ctx, s)
 }
 `
 
@@ -97,62 +222,117 @@ var (
 // assumes args already validated.
 func ExecMigrate(ctx context.Context, args []string) error {
 	var (
-		defAdapter, defDriver, defDSN = getDatabaseInfo()
-		fs                            = flag.NewFlagSet(args[1], flag.ExitOnError)
-		command                       = getMigrateCommand(args[1])
-		dir                           = fs.String("dir", "db/migrations", "Path to directory containing migration files")
-		module                        = fs.String("module", getModule(), "Module of the main package")
-		adapter                       = fs.String("adapter", defAdapter, "Adapter package")
-		driver                        = fs.String("driver", defDriver, "Driver package")
-		dsn                           = fs.String("dsn", defDSN, "DSN for database connection")
-		verbose                       = fs.Bool("verbose", false, "Show logs from REL")
-		tmpl                          = template.Must(template.New("migration").Parse(migrationTemplate))
+		fs         = flag.NewFlagSet(args[1], flag.ExitOnError)
+		command    = getMigrateCommand(args[1])
+		resolveEnv = environmentFlags(fs)
+		module     = fs.String("module", getModule(), "Module of the main package")
+		verbose    = fs.Bool("verbose", false, "Show logs from REL")
+		dryRun     = fs.Bool("dry-run", false, "Print pending migrations without applying it")
 	)
 
 	fs.Parse(args[2:])
 
-	if *adapter == "" || *driver == "" || *dsn == "" {
-		return fmt.Errorf("rel: missing required parameters:\n\tadapter: %s\n\tdriver: %s\n\tdsn: %s", *adapter, *driver, *dsn)
+	env, err := resolveEnv()
+	if err != nil {
+		return err
 	}
 
-	file, err := ioutil.TempFile(tempdir, "rel-*.go")
-	check(err)
-	defer os.Remove(file.Name())
+	if env.Adapter == "" || env.Driver == "" || env.DSN == "" {
+		return fmt.Errorf("rel: missing required parameters:\n\tadapter: %s\n\tdriver: %s\n\tdsn: %s", env.Adapter, env.Driver, env.DSN)
+	}
+
+	if *dryRun {
+		if command != "migrate" {
+			return errors.New("rel: dry-run is only supported for migrate")
+		}
 
-	migrations, err := scanMigration(*dir)
+		command = "plan"
+	}
+
+	source, err := renderRunner(env, *module, "")
 	if err != nil {
 		return err
 	}
 
-	err = tmpl.Execute(file, struct {
-		Package    string
-		Command    string
-		Adapter    string
-		Driver     string
-		DSN        string
-		Migrations []migration
-		Verbose    bool
-	}{
-		Package:    *module + "/" + *dir,
-		Command:    command,
-		Adapter:    *adapter,
-		Driver:     *driver,
-		DSN:        *dsn,
-		Migrations: migrations,
-		Verbose:    *verbose,
-	})
-	check(err)
-	check(file.Close())
+	runner, err := cachedRunner(ctx, "migrate", source, env.Dir)
+	if err != nil {
+		return err
+	}
 
-	cmd := exec.CommandContext(ctx, "go", "run", "-mod=mod", file.Name(), "migrate")
+	cmd := exec.CommandContext(ctx, runner, "-verbose="+strconv.FormatBool(*verbose), command)
+	cmd.Env = append(os.Environ(), "DATABASE_URL="+env.DSN)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 	return cmd.Run()
 }
 
+// renderRunner source code that registers and runs all migrations using migrator package,
+// so plan, dump, load and non transactional migrations are handled by the same migrator that applies them.
+// snapshot is the package of go schema snapshot to be loaded.
+func renderRunner(env environment, module string, snapshot string) ([]byte, error) {
+	var (
+		buffer bytes.Buffer
+		tmpl   = template.Must(template.New("migration").Parse(migrationTemplate))
+	)
+
+	migrations, err := scanMigration(env.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := module + "/" + env.Dir
+	if !hasGoMigration(migrations) {
+		pkg = ""
+	}
+
+	versionTable := ""
+	if env.VersionTable != defaultVersionTable {
+		versionTable = env.VersionTable
+	}
+
+	err = tmpl.Execute(&buffer, struct {
+		Package      string
+		Snapshot     string
+		Adapter      string
+		Driver       string
+		VersionTable string
+		Migrations   []migration
+	}{
+		Package:      pkg,
+		Snapshot:     snapshot,
+		Adapter:      env.Adapter,
+		Driver:       env.Driver,
+		VersionTable: versionTable,
+		Migrations:   migrations,
+	})
+	check(err)
+
+	return buffer.Bytes(), nil
+}
+
 type migration struct {
-	Version string
-	Name    string
+	Version           string
+	Name              string
+	Rollback          bool
+	SQL               bool
+	Up                []string
+	Down              []string
+	UpNoTransaction   bool
+	DownNoTransaction bool
+}
+
+// noTransactionMarker is a line in sql migration file to run it without transaction, like schema.NoTransaction.
+const noTransactionMarker = "-- rel:no-transaction"
+
+// parseSQLMigration splits sql migration file into statements using migrator.SplitStatements,
+// and reports whether the file contains no transaction marker.
+func parseSQLMigration(content string) ([]string, bool) {
+	noTransaction := false
+	for _, line := range strings.Split(content, "\n") {
+		noTransaction = noTransaction || strings.TrimSpace(line) == noTransactionMarker
+	}
+
+	return migrator.SplitStatements(content), noTransaction
 }
 
 func scanMigration(dir string) ([]migration, error) {
@@ -161,31 +341,94 @@ func scanMigration(dir string) ([]migration, error) {
 		return nil, errors.New("rel: error accessing read migration directory: " + dir)
 	}
 
-	mFiles := make([]migration, 0, len(files))
+	var (
+		mFiles   = make([]migration, 0, len(files))
+		sqlIndex = make(map[string]int)
+		sqlUp    = make(map[string]bool)
+	)
+
 	for _, f := range files {
 		if f.IsDir() {
 			continue
 		}
 
+		if result := reSQLMigrationFile.FindStringSubmatch(f.Name()); len(result) == 4 {
+			content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+			if err != nil {
+				return nil, errors.New("rel: error reading migration file: " + f.Name())
+			}
+
+			key := result[1] + "_" + result[2]
+			i, ok := sqlIndex[key]
+			if !ok {
+				i = len(mFiles)
+				sqlIndex[key] = i
+				mFiles = append(mFiles, migration{
+					Version: result[1],
+					Name:    snaker.SnakeToCamel(result[2]),
+					SQL:     true,
+				})
+			}
+
+			if result[3] == "up" {
+				mFiles[i].Up, mFiles[i].UpNoTransaction = parseSQLMigration(string(content))
+				sqlUp[key] = true
+			} else {
+				mFiles[i].Down, mFiles[i].DownNoTransaction = parseSQLMigration(string(content))
+				mFiles[i].Rollback = true
+			}
+
+			continue
+		}
+
 		result := reMigrationFile.FindStringSubmatch(f.Name())
 		if len(result) < 3 {
 			return nil, errors.New("rel: invalid migration file: " + f.Name())
 		}
 
+		var (
+			name          = snaker.SnakeToCamel(result[2])
+			rollback, err = hasFunc(filepath.Join(dir, f.Name()), "Rollback"+name)
+		)
+
+		if err != nil {
+			return nil, errors.New("rel: invalid migration file: " + f.Name())
+		}
+
 		mFiles = append(mFiles, migration{
-			Version: result[1],
-			Name:    snaker.SnakeToCamel(result[2]),
+			Version:  result[1],
+			Name:     name,
+			Rollback: rollback,
 		})
 	}
 
+	for key, i := range sqlIndex {
+		switch {
+		case !sqlUp[key]:
+			return nil, errors.New("rel: missing migration file: " + key + ".up.sql")
+		case !mFiles[i].Rollback:
+			return nil, errors.New("rel: missing migration file: " + key + ".down.sql")
+		}
+	}
+
 	return mFiles, err
 }
 
+func hasGoMigration(migrations []migration) bool {
+	for i := range migrations {
+		if !migrations[i].SQL {
+			return true
+		}
+	}
+
+	return false
+}
+
 func getMigrateCommand(cmd string) string {
 	switch cmd {
 	case "rollback", "down":
-		return "m.Rollback(ctx)"
+		return "rollback"
 	default:
-		return "m.Migrate(ctx)"
+		return "migrate"
 	}
 }
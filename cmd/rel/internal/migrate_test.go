@@ -39,6 +39,22 @@ func TestExecMigrate(t *testing.T) {
 		assert.Equal(t, errors.New("rel: error accessing read migration directory: db"), ExecMigrate(ctx, args))
 	})
 
+	t.Run("dry-run rollback", func(t *testing.T) {
+		var (
+			ctx  = context.TODO()
+			args = []string{
+				"rel",
+				"rollback",
+				"-adapter=github.com/go-rel/sqlite3",
+				"-driver=github.com/mattn/go-sqlite3",
+				"-dsn=:memory:",
+				"-dry-run",
+			}
+		)
+
+		assert.Equal(t, errors.New("rel: dry-run is only supported for migrate"), ExecMigrate(ctx, args))
+	})
+
 	t.Run("success", func(t *testing.T) {
 		var (
 			ctx  = context.TODO()
@@ -67,6 +83,44 @@ func TestExecMigrate(t *testing.T) {
 	})
 }
 
+func TestRenderRunner(t *testing.T) {
+	env := environment{
+		Adapter:      "github.com/go-rel/sqlite3",
+		Driver:       "github.com/mattn/go-sqlite3",
+		Dir:          "testdata/migrations",
+		VersionTable: defaultVersionTable,
+	}
+
+	source, err := renderRunner(env, "example.com/app", "")
+	assert.Nil(t, err)
+
+	// the same migrator is used to plan and to apply migrations.
+	assert.Contains(t, string(source), `migration "github.com/go-rel/rel/migrator"`)
+	assert.Contains(t, string(source), `case "plan":`)
+	assert.NotContains(t, string(source), `"github.com/go-rel/migration"`)
+	assert.Contains(t, string(source), `"example.com/app/testdata/migrations"`)
+}
+
+func TestRenderRunner_sqlMigrations(t *testing.T) {
+	env := environment{
+		Adapter:      "github.com/go-rel/sqlite3",
+		Driver:       "github.com/mattn/go-sqlite3",
+		Dir:          "testdata/sql_migrations",
+		VersionTable: defaultVersionTable,
+	}
+
+	source, err := renderRunner(env, "example.com/app", "")
+	assert.Nil(t, err)
+	assert.Contains(t, string(source), `m.Register(1, func(schema *rel.Schema) {
+		schema.NoTransaction()
+		schema.Exec(rel.Raw("CREATE TABLE users (id INTEGER PRIMARY KEY)"))
+		schema.Exec(rel.Raw("CREATE INDEX CONCURRENTLY users_id ON users (id)"))
+	}, func(schema *rel.Schema) {
+		schema.Exec(rel.Raw("DROP TABLE users"))
+	})`)
+	assert.NotContains(t, string(source), `"example.com/app/testdata/sql_migrations"`)
+}
+
 func TestScanMigration(t *testing.T) {
 	tests := []struct {
 		dir        string
@@ -77,11 +131,48 @@ func TestScanMigration(t *testing.T) {
 			dir: "testdata/migrations",
 			migrations: []migration{
 				{
-					Version: "1",
-					Name:    "CreateSamples",
+					Version:  "1",
+					Name:     "CreateSamples",
+					Rollback: true,
+				},
+				{
+					Version: "2",
+					Name:    "AddSamplesName",
+				},
+				{
+					Version:  "3",
+					Name:     "CreateTags",
+					Rollback: true,
+					SQL:      true,
+					Up:       []string{"CREATE TABLE tags (id INTEGER PRIMARY KEY)"},
+					Down:     []string{"DROP TABLE tags"},
+				},
+			},
+		},
+		{
+			dir: "testdata/sql_migrations",
+			migrations: []migration{
+				{
+					Version:         "1",
+					Name:            "CreateUsers",
+					Rollback:        true,
+					SQL:             true,
+					Up:              []string{"CREATE TABLE users (id INTEGER PRIMARY KEY)", "CREATE INDEX CONCURRENTLY users_id ON users (id)"},
+					Down:            []string{"DROP TABLE users"},
+					UpNoTransaction: true,
+				},
+				{
+					Version:  "2",
+					Name:     "Noop",
+					Rollback: true,
+					SQL:      true,
 				},
 			},
 		},
+		{
+			dir: "testdata/incomplete_migrations",
+			err: errors.New("rel: missing migration file: 1_create_tags.down.sql"),
+		},
 		{
 			dir: "db",
 			err: errors.New("rel: error accessing read migration directory: db"),
@@ -105,9 +196,15 @@ func TestScanMigration(t *testing.T) {
 
 }
 
+func TestHasGoMigration(t *testing.T) {
+	assert.True(t, hasGoMigration([]migration{{Version: "1"}, {Version: "2", SQL: true}}))
+	assert.False(t, hasGoMigration([]migration{{Version: "1", SQL: true}}))
+	assert.False(t, hasGoMigration(nil))
+}
+
 func TestGetMigrateCommand(t *testing.T) {
-	assert.Equal(t, "m.Rollback(ctx)", getMigrateCommand("rollback"))
-	assert.Equal(t, "m.Rollback(ctx)", getMigrateCommand("down"))
-	assert.Equal(t, "m.Migrate(ctx)", getMigrateCommand("migrate"))
-	assert.Equal(t, "m.Migrate(ctx)", getMigrateCommand("up"))
+	assert.Equal(t, "rollback", getMigrateCommand("rollback"))
+	assert.Equal(t, "rollback", getMigrateCommand("down"))
+	assert.Equal(t, "migrate", getMigrateCommand("migrate"))
+	assert.Equal(t, "migrate", getMigrateCommand("up"))
 }
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+const defaultSchemaFile = "db/schema.sql"
+
+// ExecSchema command.
+// dump writes current database schema to snapshot file, and load creates database from snapshot file.
+// snapshot file is written as go source when it has .go extension, otherwise it's written as sql.
+func ExecSchema(ctx context.Context, args []string) error {
+	if len(args) < 3 || (args[2] != "dump" && args[2] != "load") {
+		return errors.New("rel: available schema commands are: dump, load")
+	}
+
+	var (
+		command    = args[2]
+		fs         = flag.NewFlagSet(args[1]+" "+command, flag.ExitOnError)
+		resolveEnv = environmentFlags(fs)
+		module     = fs.String("module", getModule(), "Module of the main package")
+		file       = fs.String("file", defaultSchemaFile, "Path to schema snapshot file, use .go extension to write go source")
+		verbose    = fs.Bool("verbose", false, "Show logs from REL")
+	)
+
+	fs.Parse(args[3:])
+
+	env, err := resolveEnv()
+	if err != nil {
+		return err
+	}
+
+	if env.Adapter == "" || env.Driver == "" || env.DSN == "" {
+		return fmt.Errorf("rel: missing required parameters:\n\tadapter: %s\n\tdriver: %s\n\tdsn: %s", env.Adapter, env.Driver, env.DSN)
+	}
+
+	var (
+		snapshot string
+		dirs     = []string{env.Dir}
+	)
+
+	if command == "load" {
+		if _, err := os.Stat(*file); err != nil {
+			return errors.New("rel: schema file not found: " + *file)
+		}
+
+		if filepath.Ext(*file) == ".go" {
+			snapshot = *module + "/" + filepath.ToSlash(filepath.Dir(*file))
+			dirs = append(dirs, filepath.Dir(*file))
+		}
+	}
+
+	source, err := renderRunner(env, *module, snapshot)
+	if err != nil {
+		return err
+	}
+
+	runner, err := cachedRunner(ctx, "schema", source, dirs...)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, runner, "-verbose="+strconv.FormatBool(*verbose), "-schema-file="+*file, command)
+	cmd.Env = append(os.Environ(), "DATABASE_URL="+env.DSN)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  error
+	}{
+		{
+			name: "missing command",
+			args: []string{"rel", "schema"},
+			err:  errors.New("rel: available schema commands are: dump, load"),
+		},
+		{
+			name: "invalid command",
+			args: []string{"rel", "schema", "drop"},
+			err:  errors.New("rel: available schema commands are: dump, load"),
+		},
+		{
+			name: "missing required parameters",
+			args: []string{"rel", "schema", "dump"},
+			err:  errors.New("rel: missing required parameters:\n\tadapter: \n\tdriver: \n\tdsn: "),
+		},
+		{
+			name: "schema file not found",
+			args: []string{
+				"rel",
+				"schema",
+				"load",
+				"-adapter=github.com/go-rel/sqlite3",
+				"-driver=github.com/mattn/go-sqlite3",
+				"-dsn=:memory:",
+				"-file=testdata/schema.sql",
+			},
+			err: errors.New("rel: schema file not found: testdata/schema.sql"),
+		},
+		{
+			name: "invalid migration dir",
+			args: []string{
+				"rel",
+				"schema",
+				"dump",
+				"-adapter=github.com/go-rel/sqlite3",
+				"-driver=github.com/mattn/go-sqlite3",
+				"-dsn=:memory:",
+				"-dir=db",
+			},
+			err: errors.New("rel: error accessing read migration directory: db"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.err, ExecSchema(context.TODO(), test.args))
+		})
+	}
+}
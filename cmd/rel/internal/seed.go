@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"text/template"
+
+	"github.com/serenize/snaker"
+)
+
+const seedTemplate = `
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	_ "{{.Driver}}"
+	db "{{.Adapter}}"
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/seed"
+	{{- if .Package}}
+
+	{{if .Seeds}}seeds{{else}}_{{end}} "{{.Package}}"
+	{{- end}}
+)
+
+var (
+	dsn     = flag.String("dsn", os.Getenv("DATABASE_URL"), "DSN for database connection")
+	verbose = flag.Bool("verbose", false, "Show logs from REL")
+)
+
+func logger(ctx context.Context, op string, message string) func(err error) {
+	// no op for rel functions.
+	if strings.HasPrefix(op, "rel-") {
+		return func(error) {}
+	}
+
+	if op == "seed" {
+		log.Print("Running: ", op, " ", message)
+	}
+
+	t := time.Now()
+	return func(err error) {
+		duration := time.Since(t)
+		if op == "seed" {
+			log.Print("=> Done: ", op, " ", message, " in ", duration)
+		} else if *verbose {
+			log.Print("\t[duration: ", duration, " op: ", op, "] ", message)
+		}
+
+		if err != nil {
+			log.Println("\tError: ", op, " ", err)
+		}
+	}
+}
+
+func main() {
+	var (
+		ctx = context.Background()
+	)
+
+	log.SetFlags(0)
+	flag.Parse()
+
+	adapter, err := db.Open(*dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var (
+		repo = rel.New(adapter)
+		s    = seed.New(repo)
+	)
+
+	repo.Instrumentation(logger)
+	s.Instrumentation(logger)
+
+	{{range .Seeds}}
+	s.Register({{printf "%q" .Name}}, seeds.Seed{{.Func}})
+	{{- end}}
+
+	s.Run(ctx)
+}
+`
+
+// ExecSeed command.
+// runs seeds in seed directory that haven't been run.
+func ExecSeed(ctx context.Context, args []string) error {
+	var (
+		fs         = flag.NewFlagSet(args[1], flag.ExitOnError)
+		resolveEnv = environmentFlags(fs)
+		module     = fs.String("module", getModule(), "Module of the main package")
+		verbose    = fs.Bool("verbose", false, "Show logs from REL")
+	)
+
+	fs.Parse(args[2:])
+
+	env, err := resolveEnv()
+	if err != nil {
+		return err
+	}
+
+	if env.Adapter == "" || env.Driver == "" || env.DSN == "" {
+		return fmt.Errorf("rel: missing required parameters:\n\tadapter: %s\n\tdriver: %s\n\tdsn: %s", env.Adapter, env.Driver, env.DSN)
+	}
+
+	source, err := renderSeedRunner(env, *module)
+	if err != nil {
+		return err
+	}
+
+	runner, err := cachedRunner(ctx, "seed", source, env.SeedDir)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, runner, "-verbose="+strconv.FormatBool(*verbose))
+	cmd.Env = append(os.Environ(), "DATABASE_URL="+env.DSN)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// renderSeedRunner source code that registers and runs all seeds.
+// seed file named name.go is registered if it defines SeedName function,
+// seeds registered using seed.Register inside the package are also run.
+func renderSeedRunner(env environment, module string) ([]byte, error) {
+	var (
+		buffer bytes.Buffer
+		tmpl   = template.Must(template.New("seed").Parse(seedTemplate))
+	)
+
+	seeds, hasGoFile, err := scanSeed(env.SeedDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := module + "/" + filepath.ToSlash(env.SeedDir)
+	if !hasGoFile {
+		pkg = ""
+	}
+
+	err = tmpl.Execute(&buffer, struct {
+		Package string
+		Adapter string
+		Driver  string
+		Seeds   []seedFile
+	}{
+		Package: pkg,
+		Adapter: env.Adapter,
+		Driver:  env.Driver,
+		Seeds:   seeds,
+	})
+	check(err)
+
+	return buffer.Bytes(), nil
+}
+
+type seedFile struct {
+	Name string
+	Func string
+}
+
+// scanSeed returns seed functions in seed directory, and whether the directory contains go files.
+func scanSeed(dir string) ([]seedFile, bool, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, false, errors.New("rel: error accessing read seed directory: " + dir)
+	}
+
+	var (
+		seeds  []seedFile
+		goFile bool
+	)
+
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".go" {
+			continue
+		}
+
+		result := reSeedFile.FindStringSubmatch(f.Name())
+		if len(result) < 2 {
+			return nil, false, errors.New("rel: invalid seed file: " + f.Name())
+		}
+
+		var (
+			name    = snaker.SnakeToCamel(result[1])
+			ok, err = hasFunc(filepath.Join(dir, f.Name()), "Seed"+name)
+		)
+
+		if err != nil {
+			return nil, false, errors.New("rel: invalid seed file: " + f.Name())
+		}
+
+		goFile = true
+		if ok {
+			seeds = append(seeds, seedFile{Name: result[1], Func: name})
+		}
+	}
+
+	return seeds, goFile, nil
+}
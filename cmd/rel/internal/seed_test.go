@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecSeed(t *testing.T) {
+	t.Run("missing required parameters", func(t *testing.T) {
+		var (
+			ctx  = context.TODO()
+			args = []string{
+				"rel",
+				"seed",
+			}
+		)
+
+		assert.Equal(t, errors.New("rel: missing required parameters:\n\tadapter: \n\tdriver: \n\tdsn: "), ExecSeed(ctx, args))
+	})
+
+	t.Run("invalid seed dir", func(t *testing.T) {
+		var (
+			ctx  = context.TODO()
+			args = []string{
+				"rel",
+				"seed",
+				"-adapter=github.com/go-rel/sqlite3",
+				"-driver=github.com/mattn/go-sqlite3",
+				"-dsn=:memory:",
+				"-seed-dir=db",
+			}
+		)
+
+		assert.Equal(t, errors.New("rel: error accessing read seed directory: db"), ExecSeed(ctx, args))
+	})
+}
+
+func TestRenderSeedRunner(t *testing.T) {
+	source, err := renderSeedRunner(environment{
+		Adapter: "github.com/go-rel/sqlite3",
+		Driver:  "github.com/mattn/go-sqlite3",
+		SeedDir: "testdata/seeds",
+	}, "github.com/go-rel/rel/cmd/rel/internal")
+
+	assert.Nil(t, err)
+	assert.Contains(t, string(source), `seeds "github.com/go-rel/rel/cmd/rel/internal/testdata/seeds"`)
+	assert.Contains(t, string(source), `s.Register("admin_user", seeds.SeedAdminUser)`)
+	assert.NotContains(t, string(source), `s.Register("tags"`)
+}
+
+func TestScanSeed(t *testing.T) {
+	tests := []struct {
+		dir    string
+		seeds  []seedFile
+		goFile bool
+		err    error
+	}{
+		{
+			dir:    "testdata/seeds",
+			seeds:  []seedFile{{Name: "admin_user", Func: "AdminUser"}},
+			goFile: true,
+		},
+		{
+			dir: "testdata/config",
+		},
+		{
+			dir: "db",
+			err: errors.New("rel: error accessing read seed directory: db"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.dir, func(t *testing.T) {
+			seeds, goFile, err := scanSeed(test.dir)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.seeds, seeds)
+			assert.Equal(t, test.goFile, goFile)
+		})
+	}
+}
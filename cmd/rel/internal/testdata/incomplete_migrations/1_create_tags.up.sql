@@ -0,0 +1 @@
+CREATE TABLE tags (id INTEGER PRIMARY KEY);
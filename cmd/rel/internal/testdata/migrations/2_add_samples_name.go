@@ -0,0 +1,8 @@
+package migrations
+
+import "github.com/go-rel/rel"
+
+// MigrateAddSamplesName definition
+func MigrateAddSamplesName(schema *rel.Schema) {
+	schema.AddColumn("todos", "name", rel.String)
+}
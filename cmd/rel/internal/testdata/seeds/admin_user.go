@@ -0,0 +1,13 @@
+package seeds
+
+import (
+	"context"
+
+	"github.com/go-rel/rel"
+)
+
+// SeedAdminUser definition
+func SeedAdminUser(ctx context.Context, repo rel.Repository) error {
+	_, _, err := repo.Exec(ctx, "INSERT INTO todos (id) VALUES (1)")
+	return err
+}
@@ -0,0 +1,15 @@
+package seeds
+
+import (
+	"context"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/seed"
+)
+
+func init() {
+	seed.Register("tags", func(ctx context.Context, repo rel.Repository) error {
+		_, _, err := repo.Exec(ctx, "INSERT INTO tags (id) VALUES (1)")
+		return err
+	})
+}
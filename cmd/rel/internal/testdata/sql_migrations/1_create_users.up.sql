@@ -0,0 +1,3 @@
+-- rel:no-transaction
+CREATE TABLE users (id INTEGER PRIMARY KEY);
+CREATE INDEX CONCURRENTLY users_id ON users (id);
@@ -2,6 +2,9 @@ package internal
 
 import (
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"os"
 	"regexp"
@@ -9,9 +12,14 @@ import (
 )
 
 var (
-	reMigrationFile = regexp.MustCompile(`^(\d+)_([a-z_]+)\.go$`)
-	reGomod         = regexp.MustCompile(`module\s(\S+)`)
-	gomod           = "go.mod"
+	reMigrationFile        = regexp.MustCompile(`^(\d+)_([a-z_]+)\.go$`)
+	reSQLMigrationFile     = regexp.MustCompile(`^(\d+)_([a-z_]+)\.(up|down)\.sql$`)
+	reMigrationName        = regexp.MustCompile(`^[a-z_]+$`)
+	reCreateTableMigration = regexp.MustCompile(`^create_([a-z_]+)$`)
+	reAddColumnMigration   = regexp.MustCompile(`^add_([a-z_]+)_to_([a-z_]+)$`)
+	reSeedFile             = regexp.MustCompile(`^([a-z0-9_]+)\.go$`)
+	reGomod                = regexp.MustCompile(`module\s(\S+)`)
+	gomod                  = "go.mod"
 )
 
 func getDatabaseInfo() (string, string, string) {
@@ -88,6 +96,21 @@ func getModuleFromGopath() string {
 	return strings.TrimPrefix(wd, gopath)
 }
 
+func hasFunc(filename string, name string) (bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, nil, 0)
+	if err != nil {
+		return false, err
+	}
+
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -126,6 +126,19 @@ func TestGetModule(t *testing.T) {
 	})
 }
 
+func TestHasFunc(t *testing.T) {
+	found, err := hasFunc("testdata/migrations/1_create_samples.go", "RollbackCreateSamples")
+	assert.Nil(t, err)
+	assert.True(t, found)
+
+	found, err = hasFunc("testdata/migrations/2_add_samples_name.go", "RollbackAddSamplesName")
+	assert.Nil(t, err)
+	assert.False(t, found)
+
+	_, err = hasFunc("testdata/migrations/0_not_exists.go", "RollbackNotExists")
+	assert.NotNil(t, err)
+}
+
 func TestInternal(t *testing.T) {
 	assert.Panics(t, func() { check(errors.New("err")) })
 	assert.NotPanics(t, func() { check(nil) })
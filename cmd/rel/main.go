@@ -25,18 +25,26 @@ func main() {
 	)
 
 	if len(os.Args) < 2 {
-		fmt.Println("Available command are: migrate, rollback")
+		fmt.Println("Available command are: migrate, rollback, generate, build, schema, seed")
 		os.Exit(1)
 	}
 
 	switch os.Args[1] {
 	case "migrate", "up", "rollback", "down":
 		err = internal.ExecMigrate(ctx, os.Args)
+	case "generate", "g":
+		err = internal.ExecGenerate(ctx, os.Args)
+	case "build":
+		err = internal.ExecBuild(ctx, os.Args)
+	case "schema":
+		err = internal.ExecSchema(ctx, os.Args)
+	case "seed":
+		err = internal.ExecSeed(ctx, os.Args)
 	case "version", "-v", "-version":
 		fmt.Println("REL CLI " + version)
 	case "-help":
 		fmt.Println("Usage: rel [command] -help")
-		fmt.Println("Available commands: migrate, rollback")
+		fmt.Println("Available commands: migrate, rollback, generate, build, schema, seed")
 	default:
 		flag.PrintDefaults()
 		os.Exit(1)
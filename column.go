@@ -1,5 +1,10 @@
 package rel
 
+import (
+	"strconv"
+	"strings"
+)
+
 // ColumnType definition.
 type ColumnType string
 
@@ -51,6 +56,47 @@ type Column struct {
 	Options   string
 }
 
+func (c Column) detail(alter bool) string {
+	var (
+		buffer strings.Builder
+	)
+
+	if alter {
+		switch c.Op {
+		case SchemaCreate:
+			buffer.WriteString("add ")
+		case SchemaRename:
+			buffer.WriteString("rename ")
+		case SchemaDrop:
+			buffer.WriteString("drop ")
+		}
+		buffer.WriteString("column ")
+	}
+
+	buffer.WriteString(c.Name)
+
+	switch c.Op {
+	case SchemaRename:
+		buffer.WriteString(" to ")
+		buffer.WriteString(c.Rename)
+	case SchemaDrop:
+	default:
+		buffer.WriteString(" ")
+		buffer.WriteString(string(c.Type))
+		writeFlag(&buffer, c.Primary, "primary")
+		writeFlag(&buffer, c.Unique, "unique")
+		writeFlag(&buffer, c.Required, "required")
+		writeFlag(&buffer, c.Unsigned, "unsigned")
+		writeFlag(&buffer, c.Limit != 0, "limit "+strconv.Itoa(c.Limit))
+		writeFlag(&buffer, c.Precision != 0, "precision "+strconv.Itoa(c.Precision))
+		writeFlag(&buffer, c.Scale != 0, "scale "+strconv.Itoa(c.Scale))
+		writeFlag(&buffer, c.Default != nil, "default "+fmtiface(c.Default))
+	}
+
+	writeOptions(&buffer, c.Options)
+	return buffer.String()
+}
+
 func (Column) internalTableDefinition() {}
 
 func createColumn(name string, typ ColumnType, options []ColumnOption) Column {
@@ -11,7 +11,10 @@ type contextWrapper struct {
 	adapter Adapter
 }
 
-var ctxKey contextKey
+var (
+	ctxKey          contextKey
+	deletedByCtxKey contextKey = 2
+)
 
 // fetchContext and use adapter passed by context if exists.
 // it stores contextData values to struct for fast repeated access.
@@ -33,3 +36,17 @@ func wrapContext(ctx context.Context, adapter Adapter) contextWrapper {
 		adapter: adapter,
 	}
 }
+
+// WithAdapter returns a copy of ctx that makes repository use given adapter,
+// the same way transaction adapter is passed to the function inside Transaction.
+// ctx is treated as inside a transaction that's finished outside of repository,
+// callbacks registered using AfterCommit and AfterRollback inside it are discarded.
+func WithAdapter(ctx context.Context, adapter Adapter) context.Context {
+	return context.WithValue(wrapContext(ctx, adapter).ctx, hooksCtxKey, &transactionHooks{})
+}
+
+// WithDeletedBy returns a copy of ctx that stamps deleted_by field with the given value
+// whenever a record that has deleted_by field is soft deleted.
+func WithDeletedBy(ctx context.Context, deletedBy interface{}) context.Context {
+	return context.WithValue(ctx, deletedByCtxKey, deletedBy)
+}
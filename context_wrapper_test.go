@@ -35,3 +35,15 @@ func TestContextWrapper(t *testing.T) {
 		assert.Equal(t, adapter, cw.adapter)
 	})
 }
+
+func TestWithAdapter(t *testing.T) {
+	var (
+		adapter    = &testAdapter{}
+		trxAdapter = &testAdapter{result: 1}
+		ctx        = WithAdapter(context.TODO(), trxAdapter)
+		cw         = fetchContext(ctx, adapter)
+	)
+
+	assert.Equal(t, ctx, cw.ctx)
+	assert.Equal(t, trxAdapter, cw.adapter)
+}
@@ -32,6 +32,8 @@ const (
 	HasDeleted
 	// Versioning
 	HasVersioning
+	// HasHistoryTable flag, set when record embeds Historied.
+	HasHistoryTable
 )
 
 var (
@@ -43,6 +45,7 @@ var (
 	rtInt             = reflect.TypeOf(int(0))
 	rtTable           = reflect.TypeOf((*table)(nil)).Elem()
 	rtPrimary         = reflect.TypeOf((*primary)(nil)).Elem()
+	rtHistoried       = reflect.TypeOf(Historied{})
 )
 
 type table interface {
@@ -69,6 +72,8 @@ type documentData struct {
 	primaryIndex [][]int
 	preload      []string
 	flag         DocumentFlag
+	createdAt    string
+	updatedAt    string
 }
 
 // Document provides an abstraction over reflect to easily works with struct for database purpose.
@@ -351,6 +356,21 @@ func (d *documentData) addFieldIndex(name string, index []int) {
 	d.index[name] = index
 }
 
+func (d documentData) hasField(name string) bool {
+	_, ok := d.index[name]
+	return ok
+}
+
+// Stores name of the first created and updated timestamp fields.
+func (d *documentData) addTimestampField(flag DocumentFlag, name string) {
+	switch {
+	case flag == HasCreatedAt && d.createdAt == "":
+		d.createdAt = name
+	case flag == HasUpdatedAt && d.updatedAt == "":
+		d.updatedAt = name
+	}
+}
+
 // Transfer values from other document data
 func (d *documentData) mergeEmbedded(other documentData, indexPrefix int, namePrefix string) {
 	for name, path := range other.index {
@@ -366,6 +386,12 @@ func (d *documentData) mergeEmbedded(other documentData, indexPrefix int, namePr
 	}
 	d.preload = appendWithPrefix(d.preload, other.preload, namePrefix)
 	d.flag |= other.flag
+	if d.createdAt == "" && other.createdAt != "" {
+		d.createdAt = namePrefix + other.createdAt
+	}
+	if d.updatedAt == "" && other.updatedAt != "" {
+		d.updatedAt = namePrefix + other.updatedAt
+	}
 }
 
 // NewDocument used to create abstraction to work with struct.
@@ -440,6 +466,11 @@ func extractDocumentData(rt reflect.Type, skipAssoc bool) documentData {
 			typ = typ.Elem()
 		}
 
+		if typ == rtHistoried && sf.Anonymous {
+			data.flag |= HasHistoryTable
+			continue
+		}
+
 		if typ.Kind() == reflect.Struct && sf.Anonymous {
 			embedded := extractDocumentData(typ, skipAssoc)
 			embeddedName := ""
@@ -452,9 +483,10 @@ func extractDocumentData(rt reflect.Type, skipAssoc bool) documentData {
 
 		data.addFieldIndex(name, sf.Index)
 
-		if flag := extractFlag(typ, name); flag != Invalid {
+		if flag := extractFlag(typ, name, sf.Tag.Get("db")); flag != Invalid {
 			data.fields = append(data.fields, name)
 			data.flag |= flag
+			data.addTimestampField(flag, name)
 			continue
 		}
 
@@ -501,7 +533,14 @@ func extractDocumentData(rt reflect.Type, skipAssoc bool) documentData {
 	return data
 }
 
-func extractTimeFlag(name string) DocumentFlag {
+func extractTimeFlag(name string, tag string) DocumentFlag {
+	switch {
+	case strings.HasSuffix(tag, ",created"):
+		return HasCreatedAt
+	case strings.HasSuffix(tag, ",updated"):
+		return HasUpdatedAt
+	}
+
 	switch name {
 	case "created_at", "inserted_at":
 		return HasCreatedAt
@@ -527,9 +566,9 @@ func extractIntFlag(name string) DocumentFlag {
 	return Invalid
 }
 
-func extractFlag(rt reflect.Type, name string) DocumentFlag {
+func extractFlag(rt reflect.Type, name string, tag string) DocumentFlag {
 	if rt == rtTime {
-		return extractTimeFlag(name)
+		return extractTimeFlag(name, tag)
 	}
 	if rt == rtBool {
 		return extractBoolFlag(name)
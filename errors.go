@@ -28,6 +28,29 @@ var (
 	// ErrForeignKeyConstraint is an auxiliary variable for error handling.
 	// This is only to be used when checking error with errors.Is(err, ErrForeignKeyConstraint).
 	ErrForeignKeyConstraint = ConstraintError{Type: ForeignKeyConstraint}
+
+	// ErrSerializationFailure is an auxiliary variable for error handling.
+	// This is only to be used when checking error with errors.Is(err, ErrSerializationFailure).
+	ErrSerializationFailure = RetryableError{Type: SerializationFailure}
+
+	// ErrDeadlock is an auxiliary variable for error handling.
+	// This is only to be used when checking error with errors.Is(err, ErrDeadlock).
+	ErrDeadlock = RetryableError{Type: Deadlock}
+
+	// ErrUnsupportedTransactionOptions returned when transaction options is used with adapter that doesn't implement TransactionBeginner.
+	ErrUnsupportedTransactionOptions = errors.New("rel: adapter does not support transaction options")
+
+	// ErrNestedTransactionOptions returned when nested transaction is given options that differ from the outer transaction.
+	ErrNestedTransactionOptions = errors.New("rel: nested transaction options must match the outer transaction")
+
+	// ErrUnsupportedSavepoint returned when savepoint is used with adapter that doesn't implement Savepointer.
+	ErrUnsupportedSavepoint = errors.New("rel: adapter does not support savepoint")
+
+	// ErrNotSoftDeletable returned when restoring record that doesn't have deleted or deleted_at field.
+	ErrNotSoftDeletable = errors.New("rel: record is not soft deletable")
+
+	// ErrSavepointOutsideTransaction returned when savepoint is used outside of a transaction.
+	ErrSavepointOutsideTransaction = errors.New("rel: savepoint must be used inside a transaction")
 )
 
 // NotFoundError returned whenever Find returns no result.
@@ -106,3 +129,54 @@ func (ce ConstraintError) Error() string {
 
 	return ce.Type.String() + "Error"
 }
+
+// RetryableType defines the type of retryable error.
+type RetryableType int8
+
+const (
+	// SerializationFailure error type.
+	SerializationFailure RetryableType = iota
+	// Deadlock error type.
+	Deadlock
+)
+
+// String representation of the retryable type.
+func (rt RetryableType) String() string {
+	switch rt {
+	case SerializationFailure:
+		return "SerializationFailure"
+	case Deadlock:
+		return "Deadlock"
+	default:
+		return ""
+	}
+}
+
+// RetryableError returned by adapter whenever transaction fails but can be safely retried.
+type RetryableError struct {
+	Type RetryableType
+	Err  error
+}
+
+// Is returns true when target error have the same type.
+func (re RetryableError) Is(target error) bool {
+	if err, ok := target.(RetryableError); ok {
+		return re.Type == err.Type
+	}
+
+	return false
+}
+
+// Unwrap internal error returned by database driver.
+func (re RetryableError) Unwrap() error {
+	return re.Err
+}
+
+// Error message.
+func (re RetryableError) Error() string {
+	if re.Err != nil {
+		return re.Type.String() + "Error: " + re.Err.Error()
+	}
+
+	return re.Type.String() + "Error"
+}
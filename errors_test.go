@@ -95,3 +95,25 @@ func TestConstraintError_Is(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryableType(t *testing.T) {
+	assert.Equal(t, "SerializationFailure", SerializationFailure.String())
+	assert.Equal(t, "Deadlock", Deadlock.String())
+	assert.Equal(t, "", RetryableType(100).String())
+}
+
+func TestRetryableError(t *testing.T) {
+	err := RetryableError{Type: Deadlock, Err: errors.New("deadlock detected")}
+	assert.NotNil(t, err.Unwrap())
+	assert.Equal(t, "DeadlockError: deadlock detected", err.Error())
+
+	err = RetryableError{Type: SerializationFailure}
+	assert.Nil(t, err.Unwrap())
+	assert.Equal(t, "SerializationFailureError", err.Error())
+}
+
+func TestRetryableError_Is(t *testing.T) {
+	assert.True(t, RetryableError{Type: Deadlock, Err: errors.New("deadlock")}.Is(ErrDeadlock))
+	assert.False(t, RetryableError{Type: Deadlock}.Is(ErrSerializationFailure))
+	assert.False(t, RetryableError{Type: Deadlock}.Is(ErrNotFound))
+}
@@ -0,0 +1,260 @@
+// Package factory builds and inserts records with default values for tests.
+//
+// Factory is defined once for each record type:
+//
+//	factory.Define(User{}, factory.Fields{
+//		"name":  "Alice",
+//		"email": factory.Sequence(func(n int) interface{} { return fmt.Sprint("user", n, "@example.com") }),
+//	}).Trait("admin", factory.Fields{"role": "admin"})
+//
+// Only unset fields are filled, and belongs to association is built using factory of the target type
+// unless its reference field is already set.
+package factory
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-rel/rel"
+)
+
+var registry sync.Map
+
+// Fields of a factory definition.
+// Value can be a plain value, Sequence or Association.
+// Setting belongs to association to nil prevents its parent to be built.
+type Fields map[string]interface{}
+
+type sequence func(n int) interface{}
+
+// Sequence value computed using number of records built by the factory.
+func Sequence(fn func(n int) interface{}) interface{} {
+	return sequence(fn)
+}
+
+type association struct {
+	traits []string
+}
+
+// Association builds belongs to parent using its factory and given traits.
+func Association(traits ...string) interface{} {
+	return association{traits: traits}
+}
+
+// Option for building a record.
+type Option interface {
+	applyOption(o *options)
+}
+
+type options struct {
+	traits []string
+}
+
+// Trait applies fields of a named trait on top of default fields.
+type Trait string
+
+func (t Trait) applyOption(o *options) {
+	o.traits = append(o.traits, string(t))
+}
+
+// Factory of a record type.
+type Factory struct {
+	rt     reflect.Type
+	fields Fields
+	traits map[string]Fields
+	count  int64
+}
+
+// Trait defines named set of fields that can be applied when building a record.
+func (f *Factory) Trait(name string, fields Fields) *Factory {
+	f.traits[name] = fields
+	return f
+}
+
+func (f *Factory) build(doc *rel.Document, traits []string) error {
+	var (
+		n      = int(atomic.AddInt64(&f.count, 1))
+		fields = make(Fields, len(f.fields))
+		names  []string
+	)
+
+	for name, value := range f.fields {
+		fields[name] = value
+	}
+
+	for _, trait := range traits {
+		traitFields, ok := f.traits[trait]
+		if !ok {
+			return errors.New("rel: factory: undefined trait " + trait + " for " + f.rt.String())
+		}
+
+		for name, value := range traitFields {
+			fields[name] = value
+		}
+	}
+
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := fields[name]
+
+		if isBelongsTo(doc, name) {
+			if value == nil {
+				continue
+			}
+
+			assoc, ok := value.(association)
+			if !ok {
+				return errors.New("rel: factory: belongs to " + name + " of " + f.rt.String() + " must be defined using Association")
+			}
+
+			if err := buildBelongsTo(doc, name, assoc.traits); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if current, ok := doc.Value(name); ok && !isZero(current) {
+			continue
+		}
+
+		if seq, ok := value.(sequence); ok {
+			value = seq(n)
+		}
+
+		if !doc.SetValue(name, value) {
+			return errors.New("rel: factory: cannot set field " + name + " of " + f.rt.String())
+		}
+	}
+
+	for _, name := range doc.BelongsTo() {
+		if _, ok := fields[name]; ok {
+			continue
+		}
+
+		if rt, ok := doc.Type(name); ok && defined(rt) {
+			if err := buildBelongsTo(doc, name, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func buildBelongsTo(doc *rel.Document, name string, traits []string) error {
+	assoc := doc.Association(name)
+	if !assoc.IsZero() || !isZero(assoc.ReferenceValue()) {
+		return nil
+	}
+
+	target, _ := assoc.Document()
+	return build(target, traits)
+}
+
+// Define factory of a record type.
+// Defining factory for the same type replaces the previous definition.
+func Define(record interface{}, fields Fields) *Factory {
+	f := &Factory{
+		rt:     reflect.Indirect(reflect.ValueOf(record)).Type(),
+		fields: fields,
+		traits: make(map[string]Fields),
+	}
+
+	registry.Store(f.rt, f)
+	return f
+}
+
+// Build fills unset fields of record using its factory without inserting it.
+func Build(record interface{}, opts ...Option) error {
+	var (
+		o options
+	)
+
+	for i := range opts {
+		opts[i].applyOption(&o)
+	}
+
+	return build(rel.NewDocument(record), o.traits)
+}
+
+// Insert record after filling its unset fields.
+// Belongs to parents that are not persisted yet are inserted first in the same transaction.
+func Insert(ctx context.Context, repo rel.Repository, record interface{}, opts ...Option) error {
+	if err := Build(record, opts...); err != nil {
+		return err
+	}
+
+	return repo.Transaction(ctx, func(ctx context.Context) error {
+		return insert(ctx, repo, rel.NewDocument(record))
+	})
+}
+
+// MustInsert record, panics on error.
+func MustInsert(ctx context.Context, repo rel.Repository, record interface{}, opts ...Option) {
+	if err := Insert(ctx, repo, record, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// insert belongs to parents before inserting the record,
+// parents with autosave association are skipped since it's inserted by repository.
+func insert(ctx context.Context, repo rel.Repository, doc *rel.Document) error {
+	for _, name := range doc.BelongsTo() {
+		assoc := doc.Association(name)
+		if assoc.Autosave() || assoc.IsZero() {
+			continue
+		}
+
+		if target, persisted := assoc.Document(); !persisted {
+			if err := insert(ctx, repo, target); err != nil {
+				return err
+			}
+		}
+
+		if isZero(assoc.ReferenceValue()) {
+			doc.SetValue(assoc.ReferenceField(), assoc.ForeignValue())
+		}
+	}
+
+	return repo.Insert(ctx, doc)
+}
+
+func build(doc *rel.Document, traits []string) error {
+	rt := doc.ReflectValue().Type()
+
+	f, ok := registry.Load(rt)
+	if !ok {
+		return errors.New("rel: factory: undefined factory for " + rt.String())
+	}
+
+	return f.(*Factory).build(doc, traits)
+}
+
+func defined(rt reflect.Type) bool {
+	_, ok := registry.Load(rt)
+	return ok
+}
+
+func isBelongsTo(doc *rel.Document, name string) bool {
+	for _, field := range doc.BelongsTo() {
+		if field == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isZero(value interface{}) bool {
+	return value == nil || reflect.ValueOf(value).IsZero()
+}
@@ -0,0 +1,139 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/internal/testadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type Team struct {
+	ID   int
+	Name string
+}
+
+type User struct {
+	ID     int
+	Name   string
+	Email  string
+	Role   string
+	Team   Team
+	TeamID int
+}
+
+type Post struct {
+	ID       int
+	Title    string
+	Author   *User `ref:"author_id" fk:"id"`
+	AuthorID *int
+}
+
+func init() {
+	Define(Team{}, Fields{
+		"name": Sequence(func(n int) interface{} { return fmt.Sprint("team ", n) }),
+	})
+
+	Define(User{}, Fields{
+		"name":  "Alice",
+		"email": Sequence(func(n int) interface{} { return fmt.Sprint("user", n, "@example.com") }),
+		"role":  "member",
+	}).Trait("admin", Fields{"role": "admin"})
+
+	Define(&Post{}, Fields{
+		"title":  "Hello",
+		"author": Association("admin"),
+	})
+}
+
+func TestBuild(t *testing.T) {
+	var (
+		user = User{Name: "Bob"}
+	)
+
+	assert.Nil(t, Build(&user, Trait("admin")))
+	assert.Equal(t, "Bob", user.Name)
+	assert.Equal(t, "admin", user.Role)
+	assert.Regexp(t, `^user\d+@example.com$`, user.Email)
+	assert.Regexp(t, `^team \d+$`, user.Team.Name)
+	assert.Zero(t, user.TeamID)
+
+	var (
+		other = User{}
+	)
+
+	assert.Nil(t, Build(&other))
+	assert.Equal(t, "member", other.Role)
+	assert.NotEqual(t, user.Email, other.Email)
+	assert.NotEqual(t, user.Team.Name, other.Team.Name)
+}
+
+func TestBuild_association(t *testing.T) {
+	var (
+		post = Post{}
+	)
+
+	assert.Nil(t, Build(&post))
+	assert.Equal(t, "Hello", post.Title)
+	assert.NotNil(t, post.Author)
+	assert.Equal(t, "admin", post.Author.Role)
+}
+
+func TestBuild_referenceSet(t *testing.T) {
+	var (
+		authorID = 1
+		post     = Post{AuthorID: &authorID}
+		user     = User{TeamID: 2}
+	)
+
+	assert.Nil(t, Build(&post))
+	assert.Nil(t, post.Author)
+
+	assert.Nil(t, Build(&user))
+	assert.Zero(t, user.Team)
+}
+
+func TestBuild_error(t *testing.T) {
+	type Comment struct {
+		ID int
+	}
+
+	assert.Equal(t, errors.New("rel: factory: undefined factory for factory.Comment"), Build(&Comment{}))
+	assert.Equal(t, errors.New("rel: factory: undefined trait guest for factory.User"), Build(&User{}, Trait("guest")))
+}
+
+func TestInsert(t *testing.T) {
+	var (
+		user    User
+		adapter = &testadapter.Adapter{}
+		repo    = rel.New(adapter)
+	)
+
+	adapter.On("Begin").Return(nil)
+	adapter.On("Insert", rel.From("teams"), mock.Anything, rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Insert", rel.From("users"), mock.Anything, rel.OnConflict{}).Return(2, nil).Once()
+	adapter.On("Commit").Return(nil)
+
+	assert.Nil(t, Insert(context.TODO(), repo, &user, Trait("admin")))
+	assert.Equal(t, 2, user.ID)
+	assert.Equal(t, 1, user.TeamID)
+	assert.Equal(t, 1, user.Team.ID)
+	assert.Equal(t, "admin", user.Role)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestMustInsert(t *testing.T) {
+	var (
+		adapter = &testadapter.Adapter{}
+		repo    = rel.New(adapter)
+	)
+
+	assert.Panics(t, func() {
+		MustInsert(context.TODO(), repo, &User{}, Trait("guest"))
+	})
+}
@@ -0,0 +1,352 @@
+// Package fixtures loads test records from yaml or json files.
+//
+// Fixture file is keyed by table name, followed by label of each record and its fields:
+//
+//	users:
+//	  alice:
+//	    name: Alice
+//	addresses:
+//	  alice_home:
+//	    user: alice
+//	    city: Jakarta
+//
+// Belongs to association can be referenced using label of the target record,
+// the reference field will be set to primary value of the target record after it's inserted.
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-rel/rel"
+	"gopkg.in/yaml.v3"
+)
+
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+// Fixtures of registered record types.
+type Fixtures struct {
+	repo    rel.Repository
+	types   map[string]reflect.Type
+	records map[string]map[string]interface{}
+}
+
+// Register record types that can be loaded from fixture files.
+// Type is matched to fixture using its table name.
+func (f *Fixtures) Register(records ...interface{}) {
+	for _, record := range records {
+		var (
+			rt  = reflect.Indirect(reflect.ValueOf(record)).Type()
+			doc = rel.NewDocument(reflect.New(rt).Interface())
+		)
+
+		f.types[doc.Table()] = rt
+	}
+}
+
+// Get loaded record by table and label.
+// Returns pointer to record, or nil if no record is loaded with given label.
+func (f *Fixtures) Get(table string, label string) interface{} {
+	return f.records[table][label]
+}
+
+// Load fixture files inside a transaction.
+// Existing rows of every table in the files are deleted before new records are inserted,
+// tables are processed in the order of their belongs to references,
+// and rows that reference other rows of the same table are inserted after their targets.
+func (f *Fixtures) Load(ctx context.Context, filenames ...string) error {
+	data := make(map[string]map[string]map[string]interface{})
+
+	for _, filename := range filenames {
+		if err := readFile(filename, data); err != nil {
+			return err
+		}
+	}
+
+	tables, err := f.sortTables(data)
+	if err != nil {
+		return err
+	}
+
+	return f.repo.Transaction(ctx, func(ctx context.Context) error {
+		for i := range tables {
+			table := tables[len(tables)-i-1]
+			if _, err := f.repo.DeleteAny(ctx, rel.From(table)); err != nil {
+				return err
+			}
+
+			delete(f.records, table)
+		}
+
+		for _, table := range tables {
+			if err := f.insert(ctx, table, data[table]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// insert rows of a table, rows that reference other rows of the same table are inserted after their targets.
+func (f *Fixtures) insert(ctx context.Context, table string, rows map[string]map[string]interface{}) error {
+	var (
+		rt      = f.types[table]
+		doc     = rel.NewDocument(reflect.New(rt).Interface())
+		labels  = make([]string, 0, len(rows))
+		deps    = make(map[string]map[string]bool, len(rows))
+		done    = make(map[string]bool, len(rows))
+		pending = len(rows)
+	)
+
+	for label, row := range rows {
+		labels = append(labels, label)
+		deps[label] = make(map[string]bool)
+
+		for field, value := range row {
+			if target, ok := value.(string); ok && isBelongsTo(doc, field) {
+				if assoc, _ := doc.Association(field).LazyDocument(); assoc.Table() == table {
+					deps[label][target] = true
+				}
+			}
+		}
+	}
+
+	sort.Strings(labels)
+	f.records[table] = make(map[string]interface{}, len(labels))
+
+	for pending > 0 {
+		var (
+			batch []string
+		)
+
+		for _, label := range labels {
+			if !done[label] && ready(deps[label], done) {
+				batch = append(batch, label)
+			}
+		}
+
+		if len(batch) == 0 {
+			var remaining []string
+			for _, label := range labels {
+				if !done[label] {
+					remaining = append(remaining, table+"."+label)
+				}
+			}
+
+			return errors.New("rel: fixtures: circular reference between records: " + strings.Join(remaining, ", "))
+		}
+
+		if err := f.insertBatch(ctx, table, batch, rows); err != nil {
+			return err
+		}
+
+		for _, label := range batch {
+			done[label] = true
+		}
+
+		pending -= len(batch)
+	}
+
+	return nil
+}
+
+func (f *Fixtures) insertBatch(ctx context.Context, table string, labels []string, rows map[string]map[string]interface{}) error {
+	records := reflect.New(reflect.SliceOf(f.types[table]))
+	records.Elem().Set(reflect.MakeSlice(records.Elem().Type(), len(labels), len(labels)))
+
+	for i, label := range labels {
+		doc := rel.NewDocument(records.Elem().Index(i).Addr().Interface())
+		for field, value := range rows[label] {
+			if err := f.setValue(doc, field, value); err != nil {
+				return errors.New("rel: fixtures: " + table + "." + label + ": " + err.Error())
+			}
+		}
+	}
+
+	if err := f.repo.InsertAll(ctx, records.Interface()); err != nil {
+		return err
+	}
+
+	for i, label := range labels {
+		f.records[table][label] = records.Elem().Index(i).Addr().Interface()
+	}
+
+	return nil
+}
+
+func (f *Fixtures) setValue(doc *rel.Document, field string, value interface{}) error {
+	if label, ok := value.(string); ok && isBelongsTo(doc, field) {
+		var (
+			assoc     = doc.Association(field)
+			target, _ = assoc.LazyDocument()
+		)
+
+		record, ok := f.records[target.Table()][label]
+		if !ok {
+			return errors.New("missing reference " + target.Table() + "." + label)
+		}
+
+		field = assoc.ReferenceField()
+		value, _ = rel.NewDocument(record).Value(assoc.ForeignField())
+	}
+
+	if typ, ok := doc.Type(field); ok && typ == reflect.TypeOf(time.Time{}) {
+		if s, ok := value.(string); ok {
+			t, err := parseTime(s)
+			if err != nil {
+				return err
+			}
+
+			value = t
+		}
+	}
+
+	if !doc.SetValue(field, value) {
+		return errors.New("cannot set field " + field)
+	}
+
+	return nil
+}
+
+// sortTables returns tables ordered so that belongs to targets are inserted first.
+func (f *Fixtures) sortTables(data map[string]map[string]map[string]interface{}) ([]string, error) {
+	var (
+		tables = make([]string, 0, len(data))
+		deps   = make(map[string]map[string]bool, len(data))
+		sorted = make([]string, 0, len(data))
+		done   = make(map[string]bool, len(data))
+	)
+
+	for table, rows := range data {
+		rt, ok := f.types[table]
+		if !ok {
+			return nil, errors.New("rel: fixtures: unregistered table: " + table)
+		}
+
+		var (
+			doc = rel.NewDocument(reflect.New(rt).Interface())
+		)
+
+		tables = append(tables, table)
+		deps[table] = make(map[string]bool)
+
+		for _, row := range rows {
+			for field := range row {
+				if !isBelongsTo(doc, field) {
+					continue
+				}
+
+				// reference to the same table is ordered between rows when inserting.
+				target, _ := doc.Association(field).LazyDocument()
+				if _, ok := data[target.Table()]; ok && target.Table() != table {
+					deps[table][target.Table()] = true
+				}
+			}
+		}
+	}
+
+	sort.Strings(tables)
+
+	for len(sorted) < len(tables) {
+		progress := false
+
+		for _, table := range tables {
+			if done[table] || !ready(deps[table], done) {
+				continue
+			}
+
+			sorted = append(sorted, table)
+			done[table] = true
+			progress = true
+		}
+
+		if !progress {
+			var pending []string
+			for _, table := range tables {
+				if !done[table] {
+					pending = append(pending, table)
+				}
+			}
+
+			return nil, errors.New("rel: fixtures: circular reference between tables: " + strings.Join(pending, ", "))
+		}
+	}
+
+	return sorted, nil
+}
+
+func ready(deps map[string]bool, done map[string]bool) bool {
+	for dep := range deps {
+		if !done[dep] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isBelongsTo(doc *rel.Document, field string) bool {
+	for _, name := range doc.BelongsTo() {
+		if name == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.New("invalid time " + s)
+}
+
+// readFile parses yaml or json fixture file into data, json is parsed as yaml.
+func readFile(filename string, data map[string]map[string]map[string]interface{}) error {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return errors.New("rel: fixtures: error reading file: " + filename)
+	}
+
+	var (
+		tables map[string]map[string]map[string]interface{}
+	)
+
+	if err := yaml.Unmarshal(content, &tables); err != nil {
+		return errors.New("rel: fixtures: invalid file: " + filename + ": " + err.Error())
+	}
+
+	for table, rows := range tables {
+		if data[table] == nil {
+			data[table] = make(map[string]map[string]interface{}, len(rows))
+		}
+
+		for label, row := range rows {
+			data[table][label] = row
+		}
+	}
+
+	return nil
+}
+
+// New fixtures loader for given record types.
+func New(repo rel.Repository, records ...interface{}) *Fixtures {
+	f := &Fixtures{
+		repo:    repo,
+		types:   make(map[string]reflect.Type),
+		records: make(map[string]map[string]interface{}),
+	}
+
+	f.Register(records...)
+	return f
+}
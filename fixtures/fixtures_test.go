@@ -0,0 +1,150 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/internal/testadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type User struct {
+	ID     int
+	Name   string
+	BornAt time.Time
+}
+
+type Address struct {
+	ID     int
+	City   string
+	User   *User
+	UserID *int
+}
+
+type Employee struct {
+	ID        int
+	Name      string
+	Manager   *Employee
+	ManagerID *int
+}
+
+func TestFixtures_Load(t *testing.T) {
+	var (
+		ctx      = context.TODO()
+		adapter  = &testadapter.Adapter{}
+		repo     = rel.New(adapter)
+		fixtures = New(repo, User{}, &Address{})
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", rel.From("users")).Return(1, nil).Once()
+	adapter.On("Delete", rel.From("addresses")).Return(1, nil).Once()
+	adapter.On("InsertAll", rel.From("users"), mock.Anything, mock.Anything, rel.OnConflict{}).Return([]interface{}{1, 2}, nil).Once()
+	adapter.On("InsertAll", rel.From("addresses"), mock.Anything, []map[string]rel.Mutate{
+		{
+			"city":    rel.Set("city", "Jakarta"),
+			"user_id": rel.Set("user_id", 1),
+		},
+	}, rel.OnConflict{}).Return([]interface{}{3}, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, fixtures.Load(ctx, "testdata/users.yaml", "testdata/addresses.json"))
+	assert.Equal(t, &User{ID: 1, Name: "Alice", BornAt: time.Date(1992, 3, 4, 5, 6, 7, 0, time.UTC)}, fixtures.Get("users", "alice"))
+	assert.Equal(t, &User{ID: 2, Name: "Bob", BornAt: time.Date(1990, 2, 1, 0, 0, 0, 0, time.UTC)}, fixtures.Get("users", "bob"))
+	assert.Equal(t, 1, *fixtures.Get("addresses", "alice_home").(*Address).UserID)
+	assert.Nil(t, fixtures.Get("users", "carol"))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestFixtures_Load_error(t *testing.T) {
+	tests := []struct {
+		name      string
+		filenames []string
+		err       error
+	}{
+		{
+			name:      "file not exists",
+			filenames: []string{"testdata/posts.yaml"},
+			err:       errors.New("rel: fixtures: error reading file: testdata/posts.yaml"),
+		},
+		{
+			name:      "invalid file",
+			filenames: []string{"testdata/invalid.yaml"},
+			err:       errors.New("rel: fixtures: invalid file: testdata/invalid.yaml: yaml: unmarshal errors:\n  line 1: cannot unmarshal !!seq into map[string]map[string]interface {}"),
+		},
+		{
+			name:      "unregistered table",
+			filenames: []string{"testdata/users.yaml"},
+			err:       errors.New("rel: fixtures: unregistered table: users"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				adapter  = &testadapter.Adapter{}
+				fixtures = New(rel.New(adapter), Address{})
+			)
+
+			assert.Equal(t, test.err, fixtures.Load(context.TODO(), test.filenames...))
+			adapter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFixtures_Load_missingReference(t *testing.T) {
+	var (
+		adapter  = &testadapter.Adapter{}
+		fixtures = New(rel.New(adapter), User{}, Address{})
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", rel.From("addresses")).Return(0, nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, errors.New("rel: fixtures: addresses.home: missing reference users.carol"), fixtures.Load(context.TODO(), "testdata/missing_reference.yaml"))
+	adapter.AssertExpectations(t)
+}
+
+func TestFixtures_Load_selfReference(t *testing.T) {
+	var (
+		adapter  = &testadapter.Adapter{}
+		fixtures = New(rel.New(adapter), Employee{})
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", rel.From("employees")).Return(0, nil).Once()
+	adapter.On("InsertAll", rel.From("employees"), mock.Anything, []map[string]rel.Mutate{
+		{"name": rel.Set("name", "Bob"), "manager_id": rel.Set("manager_id", nil)},
+	}, rel.OnConflict{}).Return([]interface{}{1}, nil).Once()
+	adapter.On("InsertAll", rel.From("employees"), mock.Anything, []map[string]rel.Mutate{
+		{"name": rel.Set("name", "Alice"), "manager_id": rel.Set("manager_id", 1)},
+	}, rel.OnConflict{}).Return([]interface{}{2}, nil).Once()
+	adapter.On("InsertAll", rel.From("employees"), mock.Anything, []map[string]rel.Mutate{
+		{"name": rel.Set("name", "Carol"), "manager_id": rel.Set("manager_id", 2)},
+	}, rel.OnConflict{}).Return([]interface{}{3}, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, fixtures.Load(context.TODO(), "testdata/employees.yaml"))
+	assert.Equal(t, 2, *fixtures.Get("employees", "carol").(*Employee).ManagerID)
+	adapter.AssertExpectations(t)
+}
+
+func TestFixtures_Load_circularSelfReference(t *testing.T) {
+	var (
+		adapter  = &testadapter.Adapter{}
+		fixtures = New(rel.New(adapter), Employee{})
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", rel.From("employees")).Return(0, nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, errors.New("rel: fixtures: circular reference between records: employees.alice, employees.bob"), fixtures.Load(context.TODO(), "testdata/circular_employees.yaml"))
+	adapter.AssertExpectations(t)
+}
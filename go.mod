@@ -1,6 +1,7 @@
 module github.com/go-rel/rel
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/jinzhu/inflection v1.0.0
 	github.com/onsi/ginkgo v1.15.0 // indirect
 	github.com/onsi/gomega v1.10.5 // indirect
@@ -8,6 +9,7 @@ require (
 	github.com/stretchr/objx v0.3.0 // indirect
 	github.com/stretchr/testify v1.7.1
 	github.com/subosito/gotenv v1.2.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 go 1.15
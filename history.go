@@ -0,0 +1,86 @@
+package rel
+
+import (
+	"reflect"
+	"time"
+)
+
+// Historied enables version history when embedded in a record.
+// Every Update, Delete and Restore of the record copies the previous row into <table>_versions table,
+// along with valid_from and valid_to range of the copied row.
+// It's not related to optimistic locking using lock_version field.
+//
+// Only operations on a single record write history, including has one and belongs to associations deleted by cascade.
+// Bulk operations such as UpdateAny, DeleteAny, DeleteAll and RestoreAny, as well as has many associations
+// that are deleted or replaced by cascade, don't write history.
+//
+//	type User struct {
+//		rel.Historied
+//		ID   int
+//		Name string
+//	}
+//
+// Versions table can be created using Schema.CreateVersionTable.
+type Historied struct{}
+
+// Version of a record stored in versions table.
+// Record is a pointer to a new record of the same type.
+type Version struct {
+	ValidFrom time.Time
+	ValidTo   time.Time
+	Record    interface{}
+}
+
+func versionTable(table string) string {
+	return table + "_versions"
+}
+
+func scanVersions(cur Cursor, rt reflect.Type) ([]Version, error) {
+	defer cur.Close()
+
+	fields, err := cur.Fields()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		versions []Version
+	)
+
+	for cur.Next() {
+		var (
+			version  = Version{Record: reflect.New(rt).Interface()}
+			scanners = NewDocument(version.Record).Scanners(fields)
+		)
+
+		for i, field := range fields {
+			switch field {
+			case "valid_from":
+				scanners[i] = Nullable(&version.ValidFrom)
+			case "valid_to":
+				scanners[i] = Nullable(&version.ValidTo)
+			}
+		}
+
+		if err := cur.Scan(scanners...); err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+func documentTime(doc *Document, field string) time.Time {
+	switch v, _ := doc.Value(field); t := v.(type) {
+	case time.Time:
+		return t
+	case *time.Time:
+		if t != nil {
+			return *t
+		}
+	}
+
+	return time.Time{}
+}
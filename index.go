@@ -1,5 +1,9 @@
 package rel
 
+import (
+	"strings"
+)
+
 // Index definition.
 type Index struct {
 	Op       SchemaOp
@@ -16,8 +20,54 @@ func (i Index) description() string {
 	return i.Op.String() + " index " + i.Name + " on " + i.Table
 }
 
+func (i Index) detail() string {
+	var (
+		buffer strings.Builder
+	)
+
+	buffer.WriteString(i.Op.String())
+	if i.Unique {
+		buffer.WriteString(" unique")
+	}
+	buffer.WriteString(" index ")
+
+	if i.Optional {
+		if i.Op == SchemaCreate {
+			buffer.WriteString("if not exists ")
+		} else if i.Op == SchemaDrop {
+			buffer.WriteString("if exists ")
+		}
+	}
+
+	buffer.WriteString(i.Name)
+	buffer.WriteString(" on ")
+	buffer.WriteString(i.Table)
+
+	if len(i.Columns) > 0 {
+		buffer.WriteString(" (")
+		buffer.WriteString(strings.Join(i.Columns, ", "))
+		buffer.WriteString(")")
+	}
+
+	if !i.Filter.None() {
+		buffer.WriteString(" ")
+		buffer.WriteString(i.Filter.String())
+	}
+
+	writeOptions(&buffer, i.Options)
+	return buffer.String()
+}
+
 func (Index) internalMigration() {}
 
+func (i Index) reverse() (Index, error) {
+	if i.Op != SchemaCreate {
+		return Index{}, irreversibleError(i.description())
+	}
+
+	return dropIndex(i.Table, i.Name, []IndexOption{Optional(i.Optional)}), nil
+}
+
 func createIndex(table string, name string, columns []string, options []IndexOption) Index {
 	index := Index{
 		Op:      SchemaCreate,
@@ -0,0 +1,104 @@
+// Package testadapter provides mock adapter and cursor shared by tests of rel sub packages.
+package testadapter
+
+import (
+	"context"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/mock"
+)
+
+// Adapter is a mock of rel.Adapter.
+type Adapter struct {
+	mock.Mock
+}
+
+var _ rel.Adapter = (*Adapter)(nil)
+
+// Close mock.
+func (a *Adapter) Close() error {
+	args := a.Called()
+	return args.Error(0)
+}
+
+// Instrumentation mock.
+func (a *Adapter) Instrumentation(instrumenter rel.Instrumenter) {
+}
+
+// Ping mock.
+func (a *Adapter) Ping(ctx context.Context) error {
+	args := a.Called()
+	return args.Error(0)
+}
+
+// Aggregate mock.
+func (a *Adapter) Aggregate(ctx context.Context, query rel.Query, aggregate string, field string) (int, error) {
+	args := a.Called(query, aggregate, field)
+	return args.Int(0), args.Error(1)
+}
+
+// Query mock.
+func (a *Adapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	args := a.Called(query)
+	return args.Get(0).(rel.Cursor), args.Error(1)
+}
+
+// Insert mock.
+func (a *Adapter) Insert(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	args := a.Called(query, mutates, onConflict)
+	return args.Get(0), args.Error(1)
+}
+
+// InsertAll mock.
+func (a *Adapter) InsertAll(ctx context.Context, query rel.Query, primaryField string, fields []string, mutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	args := a.Called(query, fields, mutates, onConflict)
+	return args.Get(0).([]interface{}), args.Error(1)
+}
+
+// Update mock.
+func (a *Adapter) Update(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate) (int, error) {
+	args := a.Called(query, primaryField, mutates)
+	return args.Int(0), args.Error(1)
+}
+
+// Delete mock.
+func (a *Adapter) Delete(ctx context.Context, query rel.Query) (int, error) {
+	args := a.Called(query)
+	return args.Int(0), args.Error(1)
+}
+
+// Begin mock.
+// Returns the adapter itself when only error is specified as return value,
+// otherwise returns the specified transaction adapter and error.
+func (a *Adapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	args := a.Called()
+	if len(args) == 1 {
+		return a, args.Error(0)
+	}
+
+	return args.Get(0).(rel.Adapter), args.Error(1)
+}
+
+// Commit mock.
+func (a *Adapter) Commit(ctx context.Context) error {
+	args := a.Called()
+	return args.Error(0)
+}
+
+// Rollback mock.
+func (a *Adapter) Rollback(ctx context.Context) error {
+	args := a.Called()
+	return args.Error(0)
+}
+
+// Apply mock.
+func (a *Adapter) Apply(ctx context.Context, migration rel.Migration) error {
+	args := a.Called(migration)
+	return args.Error(0)
+}
+
+// Exec mock.
+func (a *Adapter) Exec(ctx context.Context, stmt string, args []interface{}) (int64, int64, error) {
+	mockArgs := a.Called(ctx, stmt, args)
+	return int64(mockArgs.Int(0)), int64(mockArgs.Int(1)), mockArgs.Error(2)
+}
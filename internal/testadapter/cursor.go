@@ -0,0 +1,48 @@
+package testadapter
+
+import (
+	"database/sql"
+)
+
+// Cursor returns rows from a slice.
+type Cursor struct {
+	Columns []string
+	Rows    [][]interface{}
+	row     int
+}
+
+// Close cursor.
+func (c *Cursor) Close() error { return nil }
+
+// Fields returns columns of the cursor.
+func (c *Cursor) Fields() ([]string, error) { return c.Columns, nil }
+
+// NopScanner returns scanner that discards value.
+func (c *Cursor) NopScanner() interface{} { return &sql.RawBytes{} }
+
+// Next moves cursor to the next row.
+func (c *Cursor) Next() bool {
+	c.row++
+	return c.row <= len(c.Rows)
+}
+
+// Row returns number of rows that have been moved to using Next.
+func (c *Cursor) Row() int {
+	return c.row
+}
+
+// Scan current row, destination can be either pointer to interface or sql.Scanner.
+func (c *Cursor) Scan(dest ...interface{}) error {
+	for i := range dest {
+		if ptr, ok := dest[i].(*interface{}); ok {
+			*ptr = c.Rows[c.row-1][i]
+			continue
+		}
+
+		if err := dest[i].(sql.Scanner).Scan(c.Rows[c.row-1][i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
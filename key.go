@@ -1,5 +1,9 @@
 package rel
 
+import (
+	"strings"
+)
+
 // KeyType definition.
 type KeyType string
 
@@ -31,6 +35,35 @@ type Key struct {
 	Options   string
 }
 
+func (k Key) detail() string {
+	var (
+		buffer strings.Builder
+	)
+
+	buffer.WriteString(strings.ToLower(string(k.Type)))
+	if k.Name != "" {
+		buffer.WriteString(" ")
+		buffer.WriteString(k.Name)
+	}
+
+	buffer.WriteString(" (")
+	buffer.WriteString(strings.Join(k.Columns, ", "))
+	buffer.WriteString(")")
+
+	if k.Type == ForeignKey {
+		buffer.WriteString(" references ")
+		buffer.WriteString(k.Reference.Table)
+		buffer.WriteString(" (")
+		buffer.WriteString(strings.Join(k.Reference.Columns, ", "))
+		buffer.WriteString(")")
+		writeFlag(&buffer, k.Reference.OnDelete != "", "on delete "+k.Reference.OnDelete)
+		writeFlag(&buffer, k.Reference.OnUpdate != "", "on update "+k.Reference.OnUpdate)
+	}
+
+	writeOptions(&buffer, k.Options)
+	return buffer.String()
+}
+
 func (Key) internalTableDefinition() {}
 
 func createKeys(columns []string, typ KeyType, options []KeyOption) Key {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-rel/rel"
@@ -41,13 +42,47 @@ func (v versions) Swap(i, j int) {
 	v[i], v[j] = v[j], v[i]
 }
 
+// Step of a migration plan.
+type Step struct {
+	Description string
+	Statement   string
+}
+
+// Plan of a pending migration version.
+type Plan struct {
+	Version int
+	Steps   []Step
+}
+
+// String returns human readable migration plan.
+func (p Plan) String() string {
+	var (
+		buffer strings.Builder
+	)
+
+	buffer.WriteString("version ")
+	buffer.WriteString(strconv.Itoa(p.Version))
+
+	for _, step := range p.Steps {
+		buffer.WriteString("\n\t- ")
+		buffer.WriteString(step.Description)
+
+		if step.Statement != "" {
+			buffer.WriteString("\n\t  ")
+			buffer.WriteString(step.Statement)
+		}
+	}
+
+	return buffer.String()
+}
+
 // Migrator is a migration manager that handles migration logic.
-//
-// Deprecated: use github.com/go-rel/migration instead
+// It's used by rel cli to plan, apply and rollback migrations, as well as dump and load schema snapshot.
 type Migrator struct {
 	repo               rel.Repository
 	instrumenter       rel.Instrumenter
 	versions           versions
+	versionTable       string
 	versionTableExists bool
 }
 
@@ -56,19 +91,33 @@ func (m *Migrator) Instrumentation(instrumenter rel.Instrumenter) {
 	m.instrumenter = instrumenter
 }
 
+// VersionTable sets custom table name used to store applied versions.
+func (m *Migrator) VersionTable(name string) {
+	m.versionTable = name
+	m.versionTableExists = false
+}
+
 // Register a migration.
+// When down is nil, it'll be derived by reversing up migrations,
+// and panics if up migrations can't be reversed.
 func (m *Migrator) Register(v int, up func(schema *rel.Schema), down func(schema *rel.Schema)) {
 	var upSchema, downSchema rel.Schema
 
 	up(&upSchema)
-	down(&downSchema)
+	if down != nil {
+		down(&downSchema)
+	} else {
+		var err error
+		downSchema, err = upSchema.Reverse()
+		check(err)
+	}
 
 	m.versions = append(m.versions, version{Version: v, up: upSchema, down: downSchema})
 }
 
 func (m Migrator) buildVersionTableDefinition() rel.Table {
 	var schema rel.Schema
-	schema.CreateTableIfNotExists(versionTable, func(t *rel.Table) {
+	schema.CreateTableIfNotExists(m.versionTable, func(t *rel.Table) {
 		t.ID("id")
 		t.BigInt("version", rel.Unsigned(true), rel.Unique(true))
 		t.DateTime("created_at")
@@ -78,19 +127,25 @@ func (m Migrator) buildVersionTableDefinition() rel.Table {
 	return schema.Migrations[0].(rel.Table)
 }
 
-func (m *Migrator) sync(ctx context.Context) {
+func (m *Migrator) sync(ctx context.Context, readonly bool) {
 	var (
 		versions versions
 		vi       int
 		adapter  = m.repo.Adapter(ctx)
 	)
 
-	if !m.versionTableExists {
+	if !m.versionTableExists && !readonly {
 		check(adapter.Apply(ctx, m.buildVersionTableDefinition()))
 		m.versionTableExists = true
 	}
 
-	m.repo.MustFindAll(ctx, &versions, rel.UsePrimary().SortAsc("version"))
+	if err := m.repo.FindAll(ctx, &versions, rel.From(m.versionTable).UsePrimary().SortAsc("version")); err != nil {
+		// nothing is applied yet when version table doesn't exist and can't be created in readonly mode.
+		if !readonly || !m.missingVersionTable(ctx) {
+			panic(err)
+		}
+	}
+
 	sort.Sort(m.versions)
 
 	for i := range m.versions {
@@ -108,9 +163,66 @@ func (m *Migrator) sync(ctx context.Context) {
 	}
 }
 
+// missingVersionTable confirms that version table doesn't exist using adapter that implements rel.SchemaIntrospector,
+// it returns false when the adapter doesn't support introspection, so query error is never mistaken for a missing table.
+func (m *Migrator) missingVersionTable(ctx context.Context) bool {
+	introspector, ok := m.repo.Adapter(ctx).(rel.SchemaIntrospector)
+	if !ok {
+		return false
+	}
+
+	migrations, err := introspector.Introspect(ctx)
+	if err != nil {
+		return false
+	}
+
+	for _, migration := range migrations {
+		if table, ok := migration.(rel.Table); ok && table.Name == m.versionTable {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Plan returns pending migrations without applying it.
+// Statement of each step will be built if adapter implements rel.MigrationBuilder.
+// Every migration is pending when version table doesn't exist yet,
+// which requires adapter that implements rel.SchemaIntrospector to confirm, otherwise the query error is panicked.
+func (m *Migrator) Plan(ctx context.Context) []Plan {
+	m.sync(ctx, true)
+
+	var (
+		plans      []Plan
+		builder, _ = m.repo.Adapter(ctx).(rel.MigrationBuilder)
+	)
+
+	for _, v := range m.versions {
+		if v.applied {
+			continue
+		}
+
+		var (
+			details = v.up.Describe()
+			plan    = Plan{Version: v.Version, Steps: make([]Step, len(details))}
+		)
+
+		for i := range details {
+			plan.Steps[i].Description = details[i]
+			if builder != nil {
+				plan.Steps[i].Statement = builder.BuildMigration(v.up.Migrations[i])
+			}
+		}
+
+		plans = append(plans, plan)
+	}
+
+	return plans
+}
+
 // Migrate to the latest schema version.
 func (m *Migrator) Migrate(ctx context.Context) {
-	m.sync(ctx)
+	m.sync(ctx, false)
 
 	for _, v := range m.versions {
 		if v.applied {
@@ -119,9 +231,9 @@ func (m *Migrator) Migrate(ctx context.Context) {
 
 		finish := m.instrumenter.Observe(ctx, "migrate", strconv.Itoa(v.Version)+" "+v.up.String())
 
-		err := m.repo.Transaction(ctx, func(ctx context.Context) error {
-			m.repo.MustInsert(ctx, &version{Version: v.Version})
+		err := m.execute(ctx, v.up.Transactional(), func(ctx context.Context) error {
 			m.run(ctx, v.up.Migrations)
+			m.insertVersion(ctx, v.Version)
 			return nil
 		})
 
@@ -132,7 +244,7 @@ func (m *Migrator) Migrate(ctx context.Context) {
 
 // Rollback migration 1 step.
 func (m *Migrator) Rollback(ctx context.Context) {
-	m.sync(ctx)
+	m.sync(ctx, false)
 
 	for i := range m.versions {
 		v := m.versions[len(m.versions)-i-1]
@@ -142,9 +254,9 @@ func (m *Migrator) Rollback(ctx context.Context) {
 
 		finish := m.instrumenter.Observe(ctx, "rollback", strconv.Itoa(v.Version)+" "+v.down.String())
 
-		err := m.repo.Transaction(ctx, func(ctx context.Context) error {
-			m.repo.MustDelete(ctx, &v)
+		err := m.execute(ctx, v.down.Transactional(), func(ctx context.Context) error {
 			m.run(ctx, v.down.Migrations)
+			m.repo.MustDeleteAny(ctx, rel.From(m.versionTable).Where(rel.Eq("id", v.ID)))
 			return nil
 		})
 
@@ -156,6 +268,104 @@ func (m *Migrator) Rollback(ctx context.Context) {
 	}
 }
 
+// Dump current database schema using adapter that implements rel.SchemaIntrospector.
+// Version of the snapshot is the latest applied migration version.
+func (m *Migrator) Dump(ctx context.Context) Snapshot {
+	m.sync(ctx, true)
+
+	introspector, ok := m.repo.Adapter(ctx).(rel.SchemaIntrospector)
+	if !ok {
+		panic("rel: adapter does not support schema introspection")
+	}
+
+	migrations, err := introspector.Introspect(ctx)
+	check(err)
+
+	var (
+		snapshot Snapshot
+	)
+
+	for _, v := range m.versions {
+		if v.applied {
+			snapshot.Version = v.Version
+		}
+	}
+
+	for _, migration := range migrations {
+		if table, ok := migration.(rel.Table); ok && table.Name == m.versionTable {
+			continue
+		}
+
+		snapshot.Schema.Migrations = append(snapshot.Schema.Migrations, migration)
+	}
+
+	return snapshot
+}
+
+// Load snapshot into empty database,
+// and marks every registered migration up to snapshot version as applied.
+func (m *Migrator) Load(ctx context.Context, snapshot Snapshot) {
+	m.sync(ctx, false)
+
+	for _, v := range m.versions {
+		if v.applied {
+			panic("rel: cannot load schema into database with applied migrations")
+		}
+	}
+
+	finish := m.instrumenter.Observe(ctx, "migrate", "load schema "+strconv.Itoa(snapshot.Version))
+
+	err := m.execute(ctx, snapshot.Schema.Transactional(), func(ctx context.Context) error {
+		m.run(ctx, snapshot.Schema.Migrations)
+
+		for _, v := range m.versions {
+			if v.Version <= snapshot.Version {
+				m.insertVersion(ctx, v.Version)
+			}
+		}
+
+		return nil
+	})
+
+	finish(err)
+	check(err)
+}
+
+func (m *Migrator) insertVersion(ctx context.Context, v int) {
+	var (
+		t       = rel.CurrentTime(m.repo)
+		mutates = map[string]rel.Mutate{
+			"version":    rel.Set("version", v),
+			"created_at": rel.Set("created_at", t),
+			"updated_at": rel.Set("updated_at", t),
+		}
+	)
+
+	_, err := m.repo.Adapter(ctx).Insert(ctx, rel.From(m.versionTable), "id", mutates, rel.OnConflict{})
+	check(err)
+}
+
+// execute fn inside a transaction when transactional is true.
+// version is always updated after all migrations succeed, so a failed non transactional migration can be retried.
+func (m *Migrator) execute(ctx context.Context, transactional bool, fn func(ctx context.Context) error) (err error) {
+	if transactional {
+		return m.repo.Transaction(ctx, fn)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			if e, ok := p.(error); ok {
+				err = e
+				return
+			}
+
+			panic(p)
+		}
+	}()
+
+	return fn(ctx)
+}
+
 func (m *Migrator) run(ctx context.Context, migrations []rel.Migration) {
 	adapter := m.repo.Adapter(ctx)
 	for _, migration := range migrations {
@@ -170,7 +380,7 @@ func (m *Migrator) run(ctx context.Context, migrations []rel.Migration) {
 
 // New migrationr.
 func New(repo rel.Repository) Migrator {
-	return Migrator{repo: repo}
+	return Migrator{repo: repo, versionTable: versionTable}
 }
 
 func check(err error) {
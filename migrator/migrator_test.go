@@ -0,0 +1,325 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/internal/testadapter"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testAdapter struct {
+	*testadapter.Adapter
+	testBuilder
+}
+
+type testIntrospector struct {
+	testAdapter
+	migrations []rel.Migration
+}
+
+func (ti testIntrospector) Introspect(ctx context.Context) ([]rel.Migration, error) {
+	return ti.migrations, nil
+}
+
+var versionQuery = rel.From(versionTable).UsePrimary().SortAsc("version")
+
+func versionCursor(versions ...int) *testadapter.Cursor {
+	var (
+		now    = time.Now()
+		cursor = &testadapter.Cursor{Columns: []string{"id", "version", "created_at", "updated_at"}}
+	)
+
+	for i, v := range versions {
+		cursor.Rows = append(cursor.Rows, []interface{}{int64(i + 1), int64(v), now, now})
+	}
+
+	return cursor
+}
+
+func register(m *Migrator) {
+	m.Register(20210101000000,
+		func(schema *rel.Schema) {
+			schema.CreateTable("users", func(t *rel.Table) {
+				t.ID("id")
+			})
+		},
+		nil,
+	)
+
+	m.Register(20210102000000,
+		func(schema *rel.Schema) {
+			schema.CreateTable("tags", func(t *rel.Table) {
+				t.ID("id")
+			})
+			schema.CreateIndex("tags", "tags_id_idx", []string{"id"})
+		},
+		nil,
+	)
+}
+
+func TestMigrator_Plan(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = testAdapter{Adapter: &testadapter.Adapter{}}
+		m       = New(rel.New(adapter))
+	)
+
+	register(&m)
+	adapter.On("Query", versionQuery).Return(versionCursor(20210101000000), nil).Once()
+
+	assert.Equal(t, []Plan{
+		{
+			Version: 20210102000000,
+			Steps: []Step{
+				{Description: "create table tags (id ID primary)", Statement: "CREATE TABLE tags ();"},
+				{Description: "create index tags_id_idx on tags (id)", Statement: "CREATE INDEX tags_id_idx"},
+			},
+		},
+	}, m.Plan(ctx))
+	adapter.AssertExpectations(t)
+}
+
+func TestMigrator_Plan_applied(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		m       = New(rel.New(adapter))
+	)
+
+	register(&m)
+	adapter.On("Query", versionQuery).Return(versionCursor(20210101000000, 20210102000000), nil).Once()
+
+	assert.Nil(t, m.Plan(ctx))
+	adapter.AssertExpectations(t)
+}
+
+func TestMigrator_Plan_missingVersionTable(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = testIntrospector{testAdapter: testAdapter{Adapter: &testadapter.Adapter{}}}
+		m       = New(rel.New(adapter))
+	)
+
+	register(&m)
+	adapter.On("Query", versionQuery).Return(&testadapter.Cursor{}, errors.New("no such table")).Once()
+
+	plans := m.Plan(ctx)
+	assert.Len(t, plans, 2)
+	assert.Equal(t, 20210101000000, plans[0].Version)
+	assert.Equal(t, 20210102000000, plans[1].Version)
+	adapter.AssertExpectations(t)
+}
+
+func TestMigrator_Plan_queryError(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = testIntrospector{
+			testAdapter: testAdapter{Adapter: &testadapter.Adapter{}},
+			migrations:  []rel.Migration{rel.Table{Name: versionTable}},
+		}
+		m = New(rel.New(adapter))
+	)
+
+	register(&m)
+	adapter.On("Query", versionQuery).Return(&testadapter.Cursor{}, errors.New("connection refused")).Once()
+
+	assert.PanicsWithError(t, "connection refused", func() {
+		m.Plan(ctx)
+	})
+	adapter.AssertExpectations(t)
+}
+
+func TestMigrator_Plan_queryErrorWithoutIntrospection(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = testAdapter{Adapter: &testadapter.Adapter{}}
+		m       = New(rel.New(adapter))
+	)
+
+	register(&m)
+	adapter.On("Query", versionQuery).Return(&testadapter.Cursor{}, errors.New("permission denied")).Once()
+
+	assert.PanicsWithError(t, "permission denied", func() {
+		m.Plan(ctx)
+	})
+	adapter.AssertExpectations(t)
+}
+
+var now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func clock() time.Time {
+	return now
+}
+
+func versionMutates(v int) interface{} {
+	return mock.MatchedBy(func(mutates map[string]rel.Mutate) bool {
+		return mutates["version"] == rel.Set("version", v) && mutates["created_at"] == rel.Set("created_at", now)
+	})
+}
+
+func registerNoTransaction(m *Migrator) (rel.Migration, rel.Migration) {
+	var (
+		users, index rel.Schema
+	)
+
+	users.CreateTable("users", func(t *rel.Table) {
+		t.ID("id")
+	})
+
+	index.NoTransaction()
+	index.CreateIndex("users", "users_id_idx", []string{"id"}, rel.Options("CONCURRENTLY"))
+
+	m.Register(20210101000000, func(schema *rel.Schema) { *schema = users }, nil)
+	m.Register(20210102000000, func(schema *rel.Schema) { *schema = index }, nil)
+
+	return users.Migrations[0], index.Migrations[0]
+}
+
+func TestMigrator_Migrate_noTransaction(t *testing.T) {
+	var (
+		ctx          = context.TODO()
+		adapter      = &testadapter.Adapter{}
+		m            = New(rel.New(adapter, rel.WithClock(clock)))
+		users, index = registerNoTransaction(&m)
+	)
+
+	adapter.On("Apply", m.buildVersionTableDefinition()).Return(nil).Once()
+	adapter.On("Query", versionQuery).Return(versionCursor(), nil).Once()
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Apply", users).Return(nil).Once()
+	adapter.On("Insert", rel.From(versionTable), versionMutates(20210101000000), rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	// executed without transaction.
+	adapter.On("Apply", index).Return(nil).Once()
+	adapter.On("Insert", rel.From(versionTable), versionMutates(20210102000000), rel.OnConflict{}).Return(2, nil).Once()
+
+	m.Migrate(ctx)
+	adapter.AssertExpectations(t)
+}
+
+func TestMigrator_Migrate_noTransactionError(t *testing.T) {
+	var (
+		ctx      = context.TODO()
+		adapter  = &testadapter.Adapter{}
+		m        = New(rel.New(adapter))
+		_, index = registerNoTransaction(&m)
+	)
+
+	adapter.On("Apply", m.buildVersionTableDefinition()).Return(nil).Once()
+	adapter.On("Query", versionQuery).Return(versionCursor(20210101000000), nil).Once()
+	adapter.On("Apply", index).Return(errors.New("error")).Once()
+
+	// version is not recorded, so the migration can be retried.
+	assert.PanicsWithError(t, "error", func() {
+		m.Migrate(ctx)
+	})
+
+	adapter.AssertExpectations(t)
+	adapter.AssertNotCalled(t, "Insert", rel.From(versionTable), mock.Anything, rel.OnConflict{})
+}
+
+func TestMigrator_Rollback_noTransaction(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		m       = New(rel.New(adapter))
+	)
+
+	registerNoTransaction(&m)
+	down, _ := m.versions[1].up.Reverse()
+
+	adapter.On("Apply", m.buildVersionTableDefinition()).Return(nil).Once()
+	adapter.On("Query", versionQuery).Return(versionCursor(20210101000000, 20210102000000), nil).Once()
+	adapter.On("Apply", down.Migrations[0]).Return(nil).Once()
+	adapter.On("Delete", rel.From(versionTable).Where(where.Eq("id", 2))).Return(1, nil).Once()
+
+	m.Rollback(ctx)
+	adapter.AssertExpectations(t)
+}
+
+func TestMigrator_Dump(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		users   = rel.Table{Op: rel.SchemaCreate, Name: "users"}
+		index   = rel.Index{Op: rel.SchemaCreate, Table: "users", Name: "users_id_idx", Columns: []string{"id"}}
+		adapter = testIntrospector{
+			testAdapter: testAdapter{Adapter: &testadapter.Adapter{}},
+			migrations:  []rel.Migration{users, rel.Table{Op: rel.SchemaCreate, Name: versionTable}, index},
+		}
+		m = New(rel.New(adapter))
+	)
+
+	register(&m)
+	adapter.On("Query", versionQuery).Return(versionCursor(20210101000000), nil).Once()
+
+	assert.Equal(t, Snapshot{
+		Version: 20210101000000,
+		Schema:  rel.Schema{Migrations: []rel.Migration{users, index}},
+	}, m.Dump(ctx))
+	adapter.AssertExpectations(t)
+}
+
+func TestMigrator_Dump_unsupported(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		m       = New(rel.New(adapter))
+	)
+
+	register(&m)
+	adapter.On("Query", versionQuery).Return(versionCursor(), nil).Once()
+
+	assert.PanicsWithValue(t, "rel: adapter does not support schema introspection", func() {
+		m.Dump(ctx)
+	})
+	adapter.AssertExpectations(t)
+}
+
+func TestMigrator_Load(t *testing.T) {
+	var (
+		ctx      = context.TODO()
+		adapter  = &testadapter.Adapter{}
+		m        = New(rel.New(adapter, rel.WithClock(clock)))
+		snapshot = NewSnapshot(20210101000000, func(schema *rel.Schema) {
+			schema.CreateTable("users", func(t *rel.Table) {
+				t.ID("id")
+			})
+		})
+	)
+
+	register(&m)
+	adapter.On("Apply", m.buildVersionTableDefinition()).Return(nil).Once()
+	adapter.On("Query", versionQuery).Return(versionCursor(), nil).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Apply", snapshot.Schema.Migrations[0]).Return(nil).Once()
+	adapter.On("Insert", rel.From(versionTable), versionMutates(20210101000000), rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	m.Load(ctx, snapshot)
+	adapter.AssertExpectations(t)
+}
+
+func TestMigrator_Load_applied(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		m       = New(rel.New(adapter))
+	)
+
+	register(&m)
+	adapter.On("Apply", m.buildVersionTableDefinition()).Return(nil).Once()
+	adapter.On("Query", versionQuery).Return(versionCursor(20210101000000), nil).Once()
+
+	assert.PanicsWithValue(t, "rel: cannot load schema into database with applied migrations", func() {
+		m.Load(ctx, NewSnapshot(20210102000000, func(schema *rel.Schema) {}))
+	})
+	adapter.AssertExpectations(t)
+}
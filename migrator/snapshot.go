@@ -0,0 +1,356 @@
+package migrator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/go-rel/rel"
+)
+
+const (
+	snapshotVersionPrefix   = "-- version: "
+	snapshotStatementMarker = "-- statement"
+)
+
+var columnMethods = map[rel.ColumnType]string{
+	rel.ID:       "ID",
+	rel.BigID:    "BigID",
+	rel.Bool:     "Bool",
+	rel.SmallInt: "SmallInt",
+	rel.Int:      "Int",
+	rel.BigInt:   "BigInt",
+	rel.Float:    "Float",
+	rel.Decimal:  "Decimal",
+	rel.String:   "String",
+	rel.Text:     "Text",
+	rel.JSON:     "JSON",
+	rel.Date:     "Date",
+	rel.DateTime: "DateTime",
+	rel.Time:     "Time",
+}
+
+// Snapshot of database schema and the latest migration version applied to it.
+type Snapshot struct {
+	Version int
+	Schema  rel.Schema
+}
+
+// NewSnapshot from schema definition function.
+func NewSnapshot(version int, fn func(schema *rel.Schema)) Snapshot {
+	snapshot := Snapshot{Version: version}
+	fn(&snapshot.Schema)
+
+	return snapshot
+}
+
+// ReadSnapshot reads sql snapshot written by WriteSQL, statements are split using SplitStatements.
+func ReadSnapshot(r io.Reader) (Snapshot, error) {
+	var (
+		snapshot Snapshot
+	)
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return snapshot, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	if !scanner.Scan() || !strings.HasPrefix(scanner.Text(), snapshotVersionPrefix) {
+		return snapshot, errors.New("rel: missing snapshot version")
+	}
+
+	snapshot.Version, err = strconv.Atoi(strings.TrimPrefix(scanner.Text(), snapshotVersionPrefix))
+	if err != nil {
+		return snapshot, errors.New("rel: invalid snapshot version: " + scanner.Text())
+	}
+
+	for _, statement := range SplitStatements(string(data)) {
+		snapshot.Schema.Exec(rel.Raw(statement))
+	}
+
+	return snapshot, nil
+}
+
+// SplitStatements splits sql script into statements, it's used to read sql snapshot and sql migration files.
+// Script is split on statement marker line (-- statement) when it contains one,
+// so function and trigger bodies containing semicolons are kept intact,
+// otherwise it's split on semicolon at the end of a line.
+// Leading comments and trailing semicolon of each statement are removed, and empty statements are skipped.
+func SplitStatements(script string) []string {
+	var (
+		lines      = strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n")
+		start      = 0
+		chunks     []string
+		statements []string
+	)
+
+	for i := range lines {
+		if strings.TrimSpace(lines[i]) == snapshotStatementMarker {
+			chunks = append(chunks, strings.Join(lines[start:i], "\n"))
+			start = i + 1
+		}
+	}
+
+	if start == 0 {
+		chunks = strings.Split(strings.Join(lines, "\n"), ";\n")
+	} else {
+		chunks = append(chunks, strings.Join(lines[start:], "\n"))
+	}
+
+	for _, chunk := range chunks {
+		if statement := strings.TrimSuffix(trimComments(chunk), ";"); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements
+}
+
+func trimComments(statement string) string {
+	var (
+		lines = strings.Split(statement, "\n")
+		i     = 0
+	)
+
+	for i < len(lines) && (strings.HasPrefix(lines[i], "--") || strings.TrimSpace(lines[i]) == "") {
+		i++
+	}
+
+	return strings.TrimSpace(strings.Join(lines[i:], "\n"))
+}
+
+// WriteSQL writes snapshot as sql statements built using builder, each statement is preceded by statement marker line.
+func (s Snapshot) WriteSQL(w io.Writer, builder rel.MigrationBuilder) error {
+	var (
+		buffer bytes.Buffer
+	)
+
+	buffer.WriteString(snapshotVersionPrefix)
+	buffer.WriteString(strconv.Itoa(s.Version))
+	buffer.WriteString("\n")
+
+	for _, migration := range s.Schema.Migrations {
+		statement := strings.TrimSuffix(strings.TrimSpace(builder.BuildMigration(migration)), ";")
+		if statement == "" {
+			continue
+		}
+
+		buffer.WriteString("\n")
+		buffer.WriteString(snapshotStatementMarker)
+		buffer.WriteString("\n")
+		buffer.WriteString(statement)
+		buffer.WriteString(";\n")
+	}
+
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// WriteGo writes snapshot as go source of the given package.
+// The source contains Version constant and Schema function that can be loaded using NewSnapshot.
+func (s Snapshot) WriteGo(w io.Writer, pkg string) error {
+	var (
+		body     bytes.Buffer
+		buffer   bytes.Buffer
+		useWhere bool
+	)
+
+	for _, migration := range s.Schema.Migrations {
+		switch v := migration.(type) {
+		case rel.Table:
+			if v.Op != rel.SchemaCreate {
+				return errors.New("rel: unsupported snapshot migration: " + v.Name)
+			}
+
+			if err := writeGoTable(&body, v); err != nil {
+				return err
+			}
+		case rel.Index:
+			if v.Op != rel.SchemaCreate {
+				return errors.New("rel: unsupported snapshot migration: " + v.Name)
+			}
+
+			useWhere = useWhere || !v.Filter.None()
+			writeGoIndex(&body, v)
+		case rel.Raw:
+			fmt.Fprintf(&body, "\tschema.Exec(rel.Raw(%q))\n", string(v))
+		default:
+			return errors.New("rel: unsupported snapshot migration")
+		}
+	}
+
+	fmt.Fprintf(&buffer, "// Code generated by rel schema dump. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	buffer.WriteString("import (\n\t\"github.com/go-rel/rel\"\n")
+	if useWhere {
+		buffer.WriteString("\t\"github.com/go-rel/rel/where\"\n")
+	}
+	buffer.WriteString(")\n\n")
+	fmt.Fprintf(&buffer, "// Version of the latest applied migration.\nconst Version = %d\n\n", s.Version)
+	buffer.WriteString("// Schema definition.\nfunc Schema(schema *rel.Schema) {\n")
+	buffer.Write(body.Bytes())
+	buffer.WriteString("}\n")
+
+	src, err := format.Source(buffer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(src)
+	return err
+}
+
+func writeGoTable(buffer *bytes.Buffer, table rel.Table) error {
+	fmt.Fprintf(buffer, "\tschema.CreateTable(%q, func(t *rel.Table) {\n", table.Name)
+
+	for _, definition := range table.Definitions {
+		switch v := definition.(type) {
+		case rel.Column:
+			method, ok := columnMethods[v.Type]
+			if !ok {
+				fmt.Fprintf(buffer, "\t\tt.Column(%q, rel.ColumnType(%q)%s)\n", v.Name, string(v.Type), columnOptions(v))
+			} else {
+				fmt.Fprintf(buffer, "\t\tt.%s(%q%s)\n", method, v.Name, columnOptions(v))
+			}
+		case rel.Key:
+			if err := writeGoKey(buffer, v); err != nil {
+				return err
+			}
+		case rel.Raw:
+			fmt.Fprintf(buffer, "\t\tt.Fragment(%q)\n", string(v))
+		}
+	}
+
+	buffer.WriteString("\t}")
+	if table.Options != "" {
+		fmt.Fprintf(buffer, ", rel.Options(%q)", table.Options)
+	}
+	buffer.WriteString(")\n")
+
+	return nil
+}
+
+func writeGoKey(buffer *bytes.Buffer, key rel.Key) error {
+	var (
+		options []string
+	)
+
+	if key.Name != "" {
+		options = append(options, fmt.Sprintf("rel.Name(%q)", key.Name))
+	}
+
+	if key.Options != "" {
+		options = append(options, fmt.Sprintf("rel.Options(%q)", key.Options))
+	}
+
+	switch key.Type {
+	case rel.PrimaryKey:
+		fmt.Fprintf(buffer, "\t\tt.PrimaryKeys(%s%s)\n", goStrings(key.Columns), joinOptions(options))
+	case rel.ForeignKey:
+		if len(key.Columns) != 1 || len(key.Reference.Columns) != 1 {
+			return errors.New("rel: unsupported composite foreign key: " + strings.Join(key.Columns, ", "))
+		}
+
+		if key.Reference.OnDelete != "" {
+			options = append(options, fmt.Sprintf("rel.OnDelete(%q)", key.Reference.OnDelete))
+		}
+
+		if key.Reference.OnUpdate != "" {
+			options = append(options, fmt.Sprintf("rel.OnUpdate(%q)", key.Reference.OnUpdate))
+		}
+
+		fmt.Fprintf(buffer, "\t\tt.ForeignKey(%q, %q, %q%s)\n", key.Columns[0], key.Reference.Table, key.Reference.Columns[0], joinOptions(options))
+	default:
+		fmt.Fprintf(buffer, "\t\tt.Unique(%s%s)\n", goStrings(key.Columns), joinOptions(options))
+	}
+
+	return nil
+}
+
+func writeGoIndex(buffer *bytes.Buffer, index rel.Index) {
+	var (
+		method  = "CreateIndex"
+		options []string
+	)
+
+	if index.Unique {
+		method = "CreateUniqueIndex"
+	}
+
+	if !index.Filter.None() {
+		options = append(options, index.Filter.String())
+	}
+
+	if index.Options != "" {
+		options = append(options, fmt.Sprintf("rel.Options(%q)", index.Options))
+	}
+
+	fmt.Fprintf(buffer, "\tschema.%s(%q, %q, %s%s)\n", method, index.Table, index.Name, goStrings(index.Columns), joinOptions(options))
+}
+
+func columnOptions(column rel.Column) string {
+	var (
+		options []string
+	)
+
+	if column.Primary && column.Type != rel.ID && column.Type != rel.BigID {
+		options = append(options, "rel.Primary(true)")
+	}
+
+	if column.Unique {
+		options = append(options, "rel.Unique(true)")
+	}
+
+	if column.Required {
+		options = append(options, "rel.Required(true)")
+	}
+
+	if column.Unsigned {
+		options = append(options, "rel.Unsigned(true)")
+	}
+
+	if column.Limit != 0 {
+		options = append(options, fmt.Sprintf("rel.Limit(%d)", column.Limit))
+	}
+
+	if column.Precision != 0 {
+		options = append(options, fmt.Sprintf("rel.Precision(%d)", column.Precision))
+	}
+
+	if column.Scale != 0 {
+		options = append(options, fmt.Sprintf("rel.Scale(%d)", column.Scale))
+	}
+
+	if column.Default != nil {
+		options = append(options, fmt.Sprintf("rel.Default(%#v)", column.Default))
+	}
+
+	if column.Options != "" {
+		options = append(options, fmt.Sprintf("rel.Options(%q)", column.Options))
+	}
+
+	return joinOptions(options)
+}
+
+func joinOptions(options []string) string {
+	if len(options) == 0 {
+		return ""
+	}
+
+	return ", " + strings.Join(options, ", ")
+}
+
+func goStrings(values []string) string {
+	quoted := make([]string, len(values))
+	for i := range values {
+		quoted[i] = strconv.Quote(values[i])
+	}
+
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
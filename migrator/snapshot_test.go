@@ -0,0 +1,201 @@
+package migrator
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBuilder struct{}
+
+func (testBuilder) BuildMigration(migration rel.Migration) string {
+	switch v := migration.(type) {
+	case rel.Table:
+		return "CREATE TABLE " + v.Name + " ();"
+	case rel.Index:
+		return "CREATE INDEX " + v.Name
+	case rel.Raw:
+		return string(v)
+	}
+
+	return ""
+}
+
+func TestSnapshot_WriteGo(t *testing.T) {
+	var (
+		buffer   bytes.Buffer
+		snapshot = NewSnapshot(20210101000000, func(schema *rel.Schema) {
+			schema.CreateTable("users", func(t *rel.Table) {
+				t.ID("id")
+				t.String("name", rel.Limit(100), rel.Required(true), rel.Default("guest"))
+				t.Decimal("balance", rel.Precision(10), rel.Scale(2))
+				t.Int("team_id", rel.Unsigned(true))
+				t.Column("location", rel.ColumnType("POINT"))
+				t.ForeignKey("team_id", "teams", "id", rel.OnDelete("CASCADE"))
+				t.Unique([]string{"name"}, rel.Name("users_name_key"))
+				t.Fragment("CHECK (balance >= 0)")
+			}, rel.Options("ENGINE=InnoDB"))
+			schema.CreateUniqueIndex("users", "users_active_name", []string{"name"}, where.Eq("active", true))
+			schema.Exec("CREATE VIEW active_users AS SELECT * FROM users")
+		})
+	)
+
+	assert.Nil(t, snapshot.WriteGo(&buffer, "schema"))
+	assert.Equal(t, `// Code generated by rel schema dump. DO NOT EDIT.
+
+package schema
+
+import (
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+)
+
+// Version of the latest applied migration.
+const Version = 20210101000000
+
+// Schema definition.
+func Schema(schema *rel.Schema) {
+	schema.CreateTable("users", func(t *rel.Table) {
+		t.ID("id")
+		t.String("name", rel.Required(true), rel.Limit(100), rel.Default("guest"))
+		t.Decimal("balance", rel.Precision(10), rel.Scale(2))
+		t.Int("team_id", rel.Unsigned(true))
+		t.Column("location", rel.ColumnType("POINT"))
+		t.ForeignKey("team_id", "teams", "id", rel.OnDelete("CASCADE"))
+		t.Unique([]string{"name"}, rel.Name("users_name_key"))
+		t.Fragment("CHECK (balance >= 0)")
+	}, rel.Options("ENGINE=InnoDB"))
+	schema.CreateUniqueIndex("users", "users_active_name", []string{"name"}, where.Eq("active", true))
+	schema.Exec(rel.Raw("CREATE VIEW active_users AS SELECT * FROM users"))
+}
+`, buffer.String())
+}
+
+func TestSnapshot_WriteGo_unsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(schema *rel.Schema)
+		err  error
+	}{
+		{
+			name: "drop table",
+			fn: func(schema *rel.Schema) {
+				schema.DropTable("users")
+			},
+			err: errors.New("rel: unsupported snapshot migration: users"),
+		},
+		{
+			name: "drop index",
+			fn: func(schema *rel.Schema) {
+				schema.DropIndex("users", "users_name")
+			},
+			err: errors.New("rel: unsupported snapshot migration: users_name"),
+		},
+		{
+			name: "do",
+			fn: func(schema *rel.Schema) {
+				schema.Do(func(repo rel.Repository) error { return nil })
+			},
+			err: errors.New("rel: unsupported snapshot migration"),
+		},
+		{
+			name: "composite foreign key",
+			fn: func(schema *rel.Schema) {
+				schema.CreateTable("users", func(t *rel.Table) {
+					t.Definitions = append(t.Definitions, rel.Key{
+						Type:      rel.ForeignKey,
+						Columns:   []string{"team_id", "org_id"},
+						Reference: rel.ForeignKeyReference{Table: "teams", Columns: []string{"id", "org_id"}},
+					})
+				})
+			},
+			err: errors.New("rel: unsupported composite foreign key: team_id, org_id"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				buffer   bytes.Buffer
+				snapshot = NewSnapshot(1, test.fn)
+			)
+
+			assert.Equal(t, test.err, snapshot.WriteGo(&buffer, "schema"))
+		})
+	}
+}
+
+func TestSnapshot_WriteSQL(t *testing.T) {
+	var (
+		buffer   bytes.Buffer
+		snapshot = NewSnapshot(3, func(schema *rel.Schema) {
+			schema.CreateTable("users", func(t *rel.Table) {
+				t.ID("id")
+			})
+			schema.CreateIndex("users", "users_name", []string{"name"})
+			schema.Do(func(repo rel.Repository) error { return nil })
+		})
+	)
+
+	assert.Nil(t, snapshot.WriteSQL(&buffer, testBuilder{}))
+	assert.Equal(t, "-- version: 3\n\n-- statement\nCREATE TABLE users ();\n\n-- statement\nCREATE INDEX users_name;\n", buffer.String())
+}
+
+func TestReadSnapshot(t *testing.T) {
+	snapshot, err := ReadSnapshot(strings.NewReader("-- version: 3\n\nCREATE TABLE users ();\n\n-- index\nCREATE INDEX users_name;\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, Snapshot{
+		Version: 3,
+		Schema: rel.Schema{
+			Migrations: []rel.Migration{
+				rel.Raw("CREATE TABLE users ()"),
+				rel.Raw("CREATE INDEX users_name"),
+			},
+		},
+	}, snapshot)
+}
+
+func TestReadSnapshot_statementMarker(t *testing.T) {
+	var (
+		function = "CREATE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n  NEW.updated_at := now();\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql"
+		trigger  = "CREATE TRIGGER users_touch BEFORE UPDATE ON users FOR EACH ROW BEGIN\n  SET NEW.updated_at = NOW();\nEND"
+		snapshot = NewSnapshot(3, func(schema *rel.Schema) {
+			schema.Exec(rel.Raw(function))
+			schema.Exec(rel.Raw(trigger))
+		})
+		buffer bytes.Buffer
+	)
+
+	assert.Nil(t, snapshot.WriteSQL(&buffer, testBuilder{}))
+
+	result, err := ReadSnapshot(&buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, snapshot, result)
+}
+
+func TestReadSnapshot_invalidVersion(t *testing.T) {
+	_, err := ReadSnapshot(strings.NewReader("CREATE TABLE users ();\n"))
+	assert.Equal(t, errors.New("rel: missing snapshot version"), err)
+
+	_, err = ReadSnapshot(strings.NewReader("-- version: latest\n"))
+	assert.Equal(t, errors.New("rel: invalid snapshot version: -- version: latest"), err)
+}
+
+func TestSplitStatements(t *testing.T) {
+	assert.Equal(t, []string{
+		"CREATE TABLE users (id INTEGER)",
+		"CREATE INDEX users_id ON users (id)",
+	}, SplitStatements("-- create users\r\nCREATE TABLE users (id INTEGER);\r\n\r\nCREATE INDEX users_id ON users (id);"))
+
+	assert.Equal(t, []string{
+		"CREATE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql",
+		"SELECT 1",
+	}, SplitStatements("-- statement\nCREATE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n  RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\n\n-- statement\nSELECT 1;\n"))
+
+	assert.Nil(t, SplitStatements("-- nothing\n"))
+}
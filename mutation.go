@@ -3,6 +3,7 @@ package rel
 import (
 	"fmt"
 	"reflect"
+	"time"
 )
 
 // Mutator is interface for a record mutator.
@@ -12,23 +13,25 @@ type Mutator interface {
 
 // Apply using given mutators.
 func Apply(doc *Document, mutators ...Mutator) Mutation {
-	return applyMutators(doc, true, true, mutators...)
+	return applyMutators(doc, nil, true, true, mutators...)
 }
 
-// apply given mutators with customized default values
-func applyMutators(doc *Document, cascade, applyStructset bool, mutators ...Mutator) Mutation {
+// apply given mutators with customized default values,
+// clock is used by mutators to set timestamp and fallbacks to Now when it's nil.
+func applyMutators(doc *Document, clock NowFunc, cascade, applyStructset bool, mutators ...Mutator) Mutation {
 	var (
 		optionsCount int
 		mutation     = Mutation{
 			Unscoped: false,
 			Reload:   false,
 			Cascade:  Cascade(cascade),
+			clock:    clock,
 		}
 	)
 
 	for i := range mutators {
 		switch mut := mutators[i].(type) {
-		case Unscoped, Reload, Cascade, OnConflict:
+		case Unscoped, Reload, Cascade, OnConflict, ForceDelete:
 			optionsCount++
 			mut.Apply(doc, &mutation)
 		default:
@@ -53,13 +56,24 @@ type AssocMutation struct {
 // Mutation represents value to be inserted or updated to database.
 // It's not safe to be used multiple time. some operation my alter mutation data.
 type Mutation struct {
-	Mutates    map[string]Mutate
-	Assoc      map[string]AssocMutation
-	OnConflict OnConflict
-	Unscoped   Unscoped
-	Reload     Reload
-	Cascade    Cascade
-	ErrorFunc  ErrorFunc
+	Mutates     map[string]Mutate
+	Assoc       map[string]AssocMutation
+	OnConflict  OnConflict
+	Unscoped    Unscoped
+	Reload      Reload
+	Cascade     Cascade
+	ForceDelete ForceDelete
+	ErrorFunc   ErrorFunc
+	clock       NowFunc
+}
+
+// now returns current time using clock of the repository that applies this mutation.
+func (m Mutation) now() time.Time {
+	if m.clock != nil {
+		return m.clock()
+	}
+
+	return Now()
 }
 
 func (m *Mutation) initMutates() {
@@ -261,6 +275,19 @@ func (c Cascade) String() string {
 	return fmt.Sprintf("rel.Cascade(%t)", c)
 }
 
+// ForceDelete permanently deletes record even when it has deleted or deleted_at field.
+// Default to false.
+type ForceDelete bool
+
+// Apply mutation.
+func (fd ForceDelete) Apply(doc *Document, mutation *Mutation) {
+	mutation.ForceDelete = fd
+}
+
+func (fd ForceDelete) String() string {
+	return fmt.Sprintf("rel.ForceDelete(%t)", fd)
+}
+
 // ErrorFunc allows conversion REL's error to Application custom errors.
 type ErrorFunc func(error) error
 
@@ -155,6 +155,19 @@ func TestApplyMutation_Cascade(t *testing.T) {
 	assert.Equal(t, "string", record.Field1)
 }
 
+func TestApplyMutation_ForceDelete(t *testing.T) {
+	var (
+		record   = TestRecord{}
+		doc      = NewDocument(&record)
+		mutation = Mutation{
+			Cascade:     true,
+			ForceDelete: true,
+		}
+	)
+
+	assert.Equal(t, mutation, applyMutators(doc, nil, true, false, ForceDelete(true)))
+}
+
 func TestMutator_String(t *testing.T) {
 	assert.Equal(t, "rel.Set(\"field\", 1)", fmt.Sprint(Set("field", 1)))
 	assert.Equal(t, "rel.Set(\"field\", true)", fmt.Sprint(Set("field", true)))
@@ -163,4 +176,5 @@ func TestMutator_String(t *testing.T) {
 	assert.Equal(t, "rel.IncBy(\"count\", 1)", fmt.Sprint(Inc("count")))
 	assert.Equal(t, "rel.SetFragment(\"field = (?, ?, ?)\", 1, true, \"value\")", fmt.Sprint(SetFragment("field = (?, ?, ?)", 1, true, "value")))
 	assert.Equal(t, "rel.Cascade(true)", fmt.Sprint(Cascade(true)))
+	assert.Equal(t, "rel.ForceDelete(true)", fmt.Sprint(ForceDelete(true)))
 }
@@ -0,0 +1,54 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+// LocalPublisher is an in-process publisher that dispatches events to subscribers by topic.
+// It's intended for tests and single process application.
+type LocalPublisher struct {
+	lock        sync.RWMutex
+	subscribers map[string][]func(ctx context.Context, event Event) error
+	published   []Event
+}
+
+// Subscribe fn to events of a topic.
+func (lp *LocalPublisher) Subscribe(topic string, fn func(ctx context.Context, event Event) error) {
+	lp.lock.Lock()
+	defer lp.lock.Unlock()
+
+	if lp.subscribers == nil {
+		lp.subscribers = make(map[string][]func(ctx context.Context, event Event) error)
+	}
+
+	lp.subscribers[topic] = append(lp.subscribers[topic], fn)
+}
+
+// Publish event to subscribers of its topic.
+// Event is only recorded as published when every subscriber succeed.
+func (lp *LocalPublisher) Publish(ctx context.Context, event Event) error {
+	lp.lock.RLock()
+	subscribers := lp.subscribers[event.Topic]
+	lp.lock.RUnlock()
+
+	for _, fn := range subscribers {
+		if err := fn(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	lp.lock.Lock()
+	lp.published = append(lp.published, event)
+	lp.lock.Unlock()
+
+	return nil
+}
+
+// Published returns events that are successfully published.
+func (lp *LocalPublisher) Published() []Event {
+	lp.lock.RLock()
+	defer lp.lock.RUnlock()
+
+	return append([]Event(nil), lp.published...)
+}
@@ -0,0 +1,107 @@
+// Package outbox implements transactional outbox.
+//
+// Events are written to outbox table inside the same transaction as the business change:
+//
+//	repo.Transaction(ctx, func(ctx context.Context) error {
+//		if err := repo.Insert(ctx, &order); err != nil {
+//			return err
+//		}
+//
+//		return ob.Publish(ctx, "order.created", order)
+//	})
+//
+// Relay polls unsent events and hands them to a Publisher,
+// so events are only published when the transaction that writes them is committed.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-rel/rel"
+)
+
+const outboxTable = "outbox_events"
+
+// Event stored in outbox table.
+type Event struct {
+	ID            int
+	Topic         string
+	Payload       string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	SentAt        *time.Time
+	CreatedAt     time.Time
+}
+
+// Outbox writes events to outbox table.
+type Outbox struct {
+	repo  rel.Repository
+	table string
+}
+
+// Table sets custom outbox table name.
+func (o *Outbox) Table(name string) {
+	o.table = name
+}
+
+// Schema creates outbox table.
+func (o Outbox) Schema(schema *rel.Schema) {
+	schema.CreateTableIfNotExists(o.table, func(t *rel.Table) {
+		t.ID("id")
+		t.String("topic")
+		t.Text("payload")
+		t.Int("attempts", rel.Default(0))
+		t.Text("last_error")
+		t.DateTime("next_attempt_at")
+		t.DateTime("sent_at")
+		t.DateTime("created_at")
+	})
+
+	schema.CreateIndex(o.table, o.table+"_pending", []string{"sent_at", "next_attempt_at"})
+}
+
+// Publish writes event to outbox table.
+// It should be called using context of the transaction that performs the business change.
+// Payload is stored as is when it's a string or bytes, other value is encoded as json.
+func (o Outbox) Publish(ctx context.Context, topic string, payload interface{}) error {
+	var (
+		data string
+		now  = rel.CurrentTime(o.repo)
+	)
+
+	switch v := payload.(type) {
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		data = string(b)
+	}
+
+	mutates := map[string]rel.Mutate{
+		"topic":           rel.Set("topic", topic),
+		"payload":         rel.Set("payload", data),
+		"attempts":        rel.Set("attempts", 0),
+		"next_attempt_at": rel.Set("next_attempt_at", now),
+		"created_at":      rel.Set("created_at", now),
+	}
+
+	_, err := o.repo.Adapter(ctx).Insert(ctx, rel.From(o.table), "id", mutates, rel.OnConflict{})
+	return err
+}
+
+// New outbox using default outbox_events table.
+func New(repo rel.Repository) Outbox {
+	return Outbox{
+		repo:  repo,
+		table: outboxTable,
+	}
+}
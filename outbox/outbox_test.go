@@ -0,0 +1,201 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/internal/testadapter"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func clock() time.Time {
+	return now
+}
+
+func TestOutbox_Publish(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = rel.New(adapter, rel.WithClock(clock))
+		ob      = New(repo)
+	)
+
+	ob.Table("events")
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Insert", rel.From("events"), map[string]rel.Mutate{
+		"topic":           rel.Set("topic", "order.created"),
+		"payload":         rel.Set("payload", `{"id":1}`),
+		"attempts":        rel.Set("attempts", 0),
+		"next_attempt_at": rel.Set("next_attempt_at", now),
+		"created_at":      rel.Set("created_at", now),
+	}, rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Insert", rel.From("events"), mock.Anything, rel.OnConflict{}).Return(2, nil).Twice()
+	adapter.On("Commit").Return(nil).Once()
+
+	err := repo.Transaction(ctx, func(ctx context.Context) error {
+		assert.Nil(t, ob.Publish(ctx, "order.created", map[string]int{"id": 1}))
+		assert.Nil(t, ob.Publish(ctx, "order.paid", "1"))
+		return ob.Publish(ctx, "order.shipped", []byte("1"))
+	})
+
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestOutbox_Publish_invalidPayload(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		ob      = New(rel.New(adapter, rel.WithClock(clock)))
+	)
+
+	assert.NotNil(t, ob.Publish(ctx, "order.created", func() {}))
+	adapter.AssertExpectations(t)
+}
+
+func TestOutbox_Schema(t *testing.T) {
+	var (
+		schema rel.Schema
+		ob     = New(nil)
+	)
+
+	ob.Schema(&schema)
+
+	assert.Len(t, schema.Migrations, 2)
+	assert.Equal(t, "outbox_events", schema.Migrations[0].(rel.Table).Name)
+	assert.Equal(t, "outbox_events_pending", schema.Migrations[1].(rel.Index).Name)
+}
+
+func pendingQuery(table string, maxAttempts int, batchSize int) rel.Query {
+	return rel.From(table).
+		Where(where.Nil("sent_at"), where.Lt("attempts", maxAttempts), where.Lte("next_attempt_at", now)).
+		Lock("FOR UPDATE SKIP LOCKED").
+		SortAsc("id").
+		Limit(batchSize)
+}
+
+func TestRelay_Poll(t *testing.T) {
+	var (
+		ctx       = context.TODO()
+		adapter   = &testadapter.Adapter{}
+		publisher = &LocalPublisher{}
+		relay     = NewRelay(rel.New(adapter, rel.WithClock(clock)), publisher)
+		cursor    = &testadapter.Cursor{
+			Columns: []string{"id", "topic", "payload", "attempts"},
+			Rows: [][]interface{}{
+				{int64(1), "order.created", `{"id":1}`, int64(0)},
+				{int64(2), "order.paid", `{"id":1}`, int64(2)},
+				{int64(3), "order.shipped", `{"id":1}`, int64(0)},
+			},
+		}
+		delivered []int
+	)
+
+	relay.BatchSize(2)
+	relay.MaxAttempts(5)
+	relay.Backoff(time.Minute)
+
+	publisher.Subscribe("order.created", func(ctx context.Context, event Event) error {
+		delivered = append(delivered, event.ID)
+		return nil
+	})
+	publisher.Subscribe("order.paid", func(ctx context.Context, event Event) error {
+		return errors.New("broker unavailable")
+	})
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", pendingQuery("outbox_events", 5, 2)).Return(cursor, nil).Once()
+	adapter.On("Update", rel.From("outbox_events").Where(where.Eq("id", 1)), "id", map[string]rel.Mutate{
+		"attempts": rel.Set("attempts", 1),
+		"sent_at":  rel.Set("sent_at", now),
+	}).Return(1, nil).Once()
+	adapter.On("Update", rel.From("outbox_events").Where(where.Eq("id", 2)), "id", map[string]rel.Mutate{
+		"attempts":        rel.Set("attempts", 3),
+		"last_error":      rel.Set("last_error", "broker unavailable"),
+		"next_attempt_at": rel.Set("next_attempt_at", now.Add(4*time.Minute)),
+	}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	count, err := relay.Poll(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []int{1}, delivered)
+	assert.Len(t, publisher.Published(), 1)
+	assert.Equal(t, "order.created", publisher.Published()[0].Topic)
+	adapter.AssertExpectations(t)
+}
+
+func TestRelay_Poll_queryError(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		relay   = NewRelay(rel.New(adapter, rel.WithClock(clock)), &LocalPublisher{})
+	)
+
+	relay.Table("events")
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", pendingQuery("events", 10, 100)).Return(&testadapter.Cursor{}, errors.New("error")).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	count, err := relay.Poll(ctx)
+	assert.Equal(t, errors.New("error"), err)
+	assert.Equal(t, 0, count)
+	adapter.AssertExpectations(t)
+}
+
+func TestRelay_Run(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.TODO())
+		adapter     = &testadapter.Adapter{}
+		relay       = NewRelay(rel.New(adapter, rel.WithClock(clock)), &LocalPublisher{})
+	)
+
+	relay.Interval(time.Millisecond)
+
+	adapter.On("Begin").Return(nil)
+	adapter.On("Query", pendingQuery("outbox_events", 10, 100)).Return(&testadapter.Cursor{}, nil)
+	adapter.On("Commit").Return(nil).Run(func(mock.Arguments) {
+		cancel()
+	})
+
+	assert.Equal(t, context.Canceled, relay.Run(ctx))
+}
+
+func TestRelay_Run_pollError(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.TODO())
+		adapter     = &testadapter.Adapter{}
+		relay       = NewRelay(rel.New(adapter, rel.WithClock(clock)), &LocalPublisher{})
+		errs        []error
+	)
+
+	relay.Interval(time.Millisecond)
+	relay.Instrumentation(func(ctx context.Context, op string, message string) func(err error) {
+		return func(err error) {
+			if op == "outbox-poll" {
+				errs = append(errs, err)
+			}
+		}
+	})
+
+	adapter.On("Begin").Return(nil)
+	adapter.On("Query", pendingQuery("outbox_events", 10, 100)).Return(&testadapter.Cursor{}, errors.New("connection refused")).Twice()
+	adapter.On("Rollback").Return(nil).Twice()
+	adapter.On("Query", pendingQuery("outbox_events", 10, 100)).Return(&testadapter.Cursor{}, nil).Once()
+	adapter.On("Commit").Return(nil).Run(func(mock.Arguments) {
+		cancel()
+	}).Once()
+
+	assert.Equal(t, context.Canceled, relay.Run(ctx))
+	assert.Equal(t, []error{errors.New("connection refused"), errors.New("connection refused"), nil}, errs)
+	adapter.AssertExpectations(t)
+}
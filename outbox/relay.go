@@ -0,0 +1,194 @@
+package outbox
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/where"
+)
+
+// Publisher publishes event to message broker.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// maxPollBackoff limits how many times interval is doubled after consecutive poll failures.
+const maxPollBackoff = 6
+
+// Relay publishes unsent events in outbox table using publisher.
+type Relay struct {
+	repo         rel.Repository
+	publisher    Publisher
+	instrumenter rel.Instrumenter
+	table        string
+	batchSize    int
+	maxAttempts  int
+	backoff      time.Duration
+	interval     time.Duration
+}
+
+// Instrumentation function.
+func (r *Relay) Instrumentation(instrumenter rel.Instrumenter) {
+	r.instrumenter = instrumenter
+}
+
+// Table sets custom outbox table name.
+func (r *Relay) Table(name string) {
+	r.table = name
+}
+
+// BatchSize sets maximum number of events published on each poll. Defaults to 100.
+func (r *Relay) BatchSize(size int) {
+	r.batchSize = size
+}
+
+// MaxAttempts sets maximum number of attempts to publish an event,
+// event that reached maximum attempts is no longer published. Defaults to 10.
+func (r *Relay) MaxAttempts(attempts int) {
+	r.maxAttempts = attempts
+}
+
+// Backoff sets wait duration before the event is retried after the first failed attempt,
+// the duration is doubled for each subsequent failed attempt. Defaults to 1 second.
+func (r *Relay) Backoff(backoff time.Duration) {
+	r.backoff = backoff
+}
+
+// Interval sets wait duration between polls when there's no pending event. Defaults to 1 second.
+func (r *Relay) Interval(interval time.Duration) {
+	r.interval = interval
+}
+
+// Run polls and publishes events until context is canceled.
+// Poll error is reported to instrumenter and the poll is retried after interval,
+// which is doubled for each consecutive failure up to 64 times of interval.
+func (r *Relay) Run(ctx context.Context) error {
+	var (
+		failures int
+	)
+
+	for {
+		var (
+			finish     = r.instrumenter.Observe(ctx, "outbox-poll", r.table)
+			count, err = r.Poll(ctx)
+			wait       = r.interval
+		)
+
+		finish(err)
+
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			wait = r.interval << uint(failures)
+			if failures < maxPollBackoff {
+				failures++
+			}
+		case count >= r.batchSize:
+			failures = 0
+			continue
+		default:
+			failures = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Poll publishes a batch of pending events and returns number of events processed.
+// Events are locked using FOR UPDATE SKIP LOCKED, so multiple relays can run concurrently.
+func (r *Relay) Poll(ctx context.Context) (int, error) {
+	var (
+		count int
+	)
+
+	err := r.repo.Transaction(ctx, func(ctx context.Context) error {
+		events, err := r.fetch(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := range events {
+			if err := r.publish(ctx, events[i]); err != nil {
+				return err
+			}
+		}
+
+		count = len(events)
+		return nil
+	})
+
+	return count, err
+}
+
+// fetch pending events before publishing, so the cursor is closed before events are updated.
+func (r *Relay) fetch(ctx context.Context) ([]Event, error) {
+	var (
+		events []Event
+		query  = rel.From(r.table).
+			Where(where.Nil("sent_at"), where.Lt("attempts", r.maxAttempts), where.Lte("next_attempt_at", rel.CurrentTime(r.repo))).
+			Lock("FOR UPDATE SKIP LOCKED")
+		it = r.repo.Iterate(ctx, query, rel.BatchSize(r.batchSize))
+	)
+
+	defer it.Close()
+
+	for len(events) < r.batchSize {
+		var (
+			event Event
+		)
+
+		if err := it.Next(&event); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// publish event and update its bookkeeping, failed event is scheduled to be retried using exponential backoff.
+func (r *Relay) publish(ctx context.Context, event Event) error {
+	var (
+		finish  = r.instrumenter.Observe(ctx, "outbox-publish", event.Topic)
+		err     = r.publisher.Publish(ctx, event)
+		now     = rel.CurrentTime(r.repo)
+		mutates = map[string]rel.Mutate{
+			"attempts": rel.Set("attempts", event.Attempts+1),
+		}
+	)
+
+	finish(err)
+
+	if err == nil {
+		mutates["sent_at"] = rel.Set("sent_at", now)
+	} else {
+		mutates["last_error"] = rel.Set("last_error", err.Error())
+		mutates["next_attempt_at"] = rel.Set("next_attempt_at", now.Add(r.backoff<<uint(event.Attempts)))
+	}
+
+	_, err = r.repo.Adapter(ctx).Update(ctx, rel.From(r.table).Where(where.Eq("id", event.ID)), "id", mutates)
+	return err
+}
+
+// NewRelay for events in default outbox_events table.
+func NewRelay(repo rel.Repository, publisher Publisher) *Relay {
+	return &Relay{
+		repo:        repo,
+		publisher:   publisher,
+		table:       outboxTable,
+		batchSize:   100,
+		maxAttempts: 10,
+		backoff:     time.Second,
+		interval:    time.Second,
+	}
+}
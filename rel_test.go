@@ -88,6 +88,46 @@ type History struct {
 	TransactionID int
 }
 
+type Article struct {
+	Historied
+	ID        int
+	Title     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+}
+
+type Post struct {
+	ID          int
+	Title       string
+	Summary     *PostSummary `autosave:"true"`
+	Comments    []Comment    `autosave:"true"`
+	Attachments []Attachment `autosave:"true"`
+	DeletedAt   *time.Time
+}
+
+type PostSummary struct {
+	ID        int
+	PostID    int
+	Body      string
+	DeletedAt *time.Time
+}
+
+type Attachment struct {
+	ID     int
+	PostID int
+	Name   string
+}
+
+type Comment struct {
+	ID        int
+	PostID    int
+	Body      string
+	Deleted   bool
+	DeletedBy string
+	UpdatedAt time.Time
+}
+
 type Notes string
 
 func (n Notes) Equal(other interface{}) bool {
@@ -0,0 +1,337 @@
+package reltest
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/go-rel/rel"
+)
+
+// Fault to be injected to adapter operation.
+// Available operations are: ping, aggregate, query, scan, insert, insert-all, update, delete, exec, begin, commit, rollback,
+// savepoint, rollback-to, release and apply.
+// Scan fault is checked once for every row scanned from cursor.
+type Fault struct {
+	op          string
+	table       string
+	err         error
+	nth         int
+	probability float64
+	rand        *rand.Rand
+	count       int
+}
+
+// Fail creates a fault that fails every call to operation.
+func Fail(op string) *Fault {
+	return &Fault{
+		op:  op,
+		err: errors.New("reltest: injected " + op + " fault"),
+	}
+}
+
+// Table limits fault to operation on given table.
+func (f *Fault) Table(table string) *Fault {
+	f.table = table
+	return f
+}
+
+// Error returned by the failed operation.
+func (f *Fault) Error(err error) *Fault {
+	f.err = err
+	return f
+}
+
+// Timeout makes the failed operation returns context.DeadlineExceeded.
+func (f *Fault) Timeout() *Fault {
+	return f.Error(context.DeadlineExceeded)
+}
+
+// Nth only fails the nth matching call, starting from 1.
+func (f *Fault) Nth(n int) *Fault {
+	f.nth = n
+	return f
+}
+
+// Probability of every matching call to fail.
+// Random number is generated using the given seed so the result can be reproduced.
+func (f *Fault) Probability(p float64, seed int64) *Fault {
+	f.probability = p
+	f.rand = rand.New(rand.NewSource(seed))
+	return f
+}
+
+func (f *Fault) trigger(op string, table string) bool {
+	if f.op != op || (f.table != "" && f.table != table) {
+		return false
+	}
+
+	f.count++
+
+	switch {
+	case f.nth > 0:
+		return f.count == f.nth
+	case f.rand != nil:
+		return f.rand.Float64() < f.probability
+	default:
+		return true
+	}
+}
+
+type faults struct {
+	lock   sync.Mutex
+	faults []*Fault
+}
+
+func (fs *faults) check(op string, table string) error {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	var (
+		err error
+	)
+
+	// every matching fault counts the call, the first triggered fault is returned.
+	for _, f := range fs.faults {
+		if f.trigger(op, table) && err == nil {
+			err = f.err
+		}
+	}
+
+	return err
+}
+
+// FaultAdapter is an adapter decorator that injects failures to the wrapped adapter.
+// Failed operation is not forwarded, except failed commit that rollbacks the transaction.
+type FaultAdapter struct {
+	adapter rel.Adapter
+	faults  *faults
+}
+
+var (
+	_ rel.Adapter             = (*FaultAdapter)(nil)
+	_ rel.TransactionBeginner = (*FaultAdapter)(nil)
+	_ rel.Savepointer         = (*FaultAdapter)(nil)
+)
+
+// InjectFaults to adapter.
+func InjectFaults(adapter rel.Adapter, faultList ...*Fault) *FaultAdapter {
+	return &FaultAdapter{
+		adapter: adapter,
+		faults:  &faults{faults: faultList},
+	}
+}
+
+// Close database connection.
+func (fa *FaultAdapter) Close() error {
+	return fa.adapter.Close()
+}
+
+// Instrumentation set instrumenter of the wrapped adapter.
+func (fa *FaultAdapter) Instrumentation(instrumenter rel.Instrumenter) {
+	fa.adapter.Instrumentation(instrumenter)
+}
+
+// Ping database.
+func (fa *FaultAdapter) Ping(ctx context.Context) error {
+	if err := fa.faults.check("ping", ""); err != nil {
+		return err
+	}
+
+	return fa.adapter.Ping(ctx)
+}
+
+// Aggregate query.
+func (fa *FaultAdapter) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
+	if err := fa.faults.check("aggregate", query.Table); err != nil {
+		return 0, err
+	}
+
+	return fa.adapter.Aggregate(ctx, query, mode, field)
+}
+
+// Query database.
+func (fa *FaultAdapter) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	if err := fa.faults.check("query", query.Table); err != nil {
+		return nil, err
+	}
+
+	cursor, err := fa.adapter.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &faultCursor{Cursor: cursor, table: query.Table, faults: fa.faults}, nil
+}
+
+// Insert a record.
+func (fa *FaultAdapter) Insert(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	if err := fa.faults.check("insert", query.Table); err != nil {
+		return nil, err
+	}
+
+	return fa.adapter.Insert(ctx, query, primaryField, mutates, onConflict)
+}
+
+// InsertAll records.
+func (fa *FaultAdapter) InsertAll(ctx context.Context, query rel.Query, primaryField string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	if err := fa.faults.check("insert-all", query.Table); err != nil {
+		return nil, err
+	}
+
+	return fa.adapter.InsertAll(ctx, query, primaryField, fields, bulkMutates, onConflict)
+}
+
+// Update records.
+func (fa *FaultAdapter) Update(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate) (int, error) {
+	if err := fa.faults.check("update", query.Table); err != nil {
+		return 0, err
+	}
+
+	return fa.adapter.Update(ctx, query, primaryField, mutates)
+}
+
+// Delete records.
+func (fa *FaultAdapter) Delete(ctx context.Context, query rel.Query) (int, error) {
+	if err := fa.faults.check("delete", query.Table); err != nil {
+		return 0, err
+	}
+
+	return fa.adapter.Delete(ctx, query)
+}
+
+// Exec raw statement.
+func (fa *FaultAdapter) Exec(ctx context.Context, stmt string, args []interface{}) (int64, int64, error) {
+	if err := fa.faults.check("exec", ""); err != nil {
+		return 0, 0, err
+	}
+
+	return fa.adapter.Exec(ctx, stmt, args)
+}
+
+// Begin transaction.
+func (fa *FaultAdapter) Begin(ctx context.Context) (rel.Adapter, error) {
+	if err := fa.faults.check("begin", ""); err != nil {
+		return nil, err
+	}
+
+	adapter, err := fa.adapter.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FaultAdapter{adapter: adapter, faults: fa.faults}, nil
+}
+
+// BeginTransaction with options.
+func (fa *FaultAdapter) BeginTransaction(ctx context.Context, options rel.TransactionOptions) (rel.Adapter, error) {
+	if err := fa.faults.check("begin", ""); err != nil {
+		return nil, err
+	}
+
+	beginner, ok := fa.adapter.(rel.TransactionBeginner)
+	if !ok {
+		return nil, rel.ErrUnsupportedTransactionOptions
+	}
+
+	adapter, err := beginner.BeginTransaction(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FaultAdapter{adapter: adapter, faults: fa.faults}, nil
+}
+
+// Commit transaction.
+func (fa *FaultAdapter) Commit(ctx context.Context) error {
+	if err := fa.faults.check("commit", ""); err != nil {
+		_ = fa.adapter.Rollback(ctx)
+		return err
+	}
+
+	return fa.adapter.Commit(ctx)
+}
+
+// Rollback transaction.
+func (fa *FaultAdapter) Rollback(ctx context.Context) error {
+	if err := fa.faults.check("rollback", ""); err != nil {
+		return err
+	}
+
+	return fa.adapter.Rollback(ctx)
+}
+
+// Savepoint creates a named savepoint.
+func (fa *FaultAdapter) Savepoint(ctx context.Context, name string) error {
+	if err := fa.faults.check("savepoint", ""); err != nil {
+		return err
+	}
+
+	savepointer, ok := fa.adapter.(rel.Savepointer)
+	if !ok {
+		return rel.ErrUnsupportedSavepoint
+	}
+
+	return savepointer.Savepoint(ctx, name)
+}
+
+// RollbackTo a named savepoint.
+func (fa *FaultAdapter) RollbackTo(ctx context.Context, name string) error {
+	if err := fa.faults.check("rollback-to", ""); err != nil {
+		return err
+	}
+
+	savepointer, ok := fa.adapter.(rel.Savepointer)
+	if !ok {
+		return rel.ErrUnsupportedSavepoint
+	}
+
+	return savepointer.RollbackTo(ctx, name)
+}
+
+// Release a named savepoint.
+func (fa *FaultAdapter) Release(ctx context.Context, name string) error {
+	if err := fa.faults.check("release", ""); err != nil {
+		return err
+	}
+
+	savepointer, ok := fa.adapter.(rel.Savepointer)
+	if !ok {
+		return rel.ErrUnsupportedSavepoint
+	}
+
+	return savepointer.Release(ctx, name)
+}
+
+// Apply migration.
+func (fa *FaultAdapter) Apply(ctx context.Context, migration rel.Migration) error {
+	if err := fa.faults.check("apply", ""); err != nil {
+		return err
+	}
+
+	return fa.adapter.Apply(ctx, migration)
+}
+
+type faultCursor struct {
+	rel.Cursor
+	table   string
+	faults  *faults
+	checked bool
+}
+
+func (fc *faultCursor) Next() bool {
+	fc.checked = false
+	return fc.Cursor.Next()
+}
+
+func (fc *faultCursor) Scan(dest ...interface{}) error {
+	if !fc.checked {
+		fc.checked = true
+		if err := fc.faults.check("scan", fc.table); err != nil {
+			return err
+		}
+	}
+
+	return fc.Cursor.Scan(dest...)
+}
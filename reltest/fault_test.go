@@ -0,0 +1,183 @@
+package reltest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/internal/testadapter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFaultAdapter_insert(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		cerr    = rel.ConstraintError{Key: "users_name_key", Type: rel.UniqueConstraint}
+		repo    = rel.New(InjectFaults(adapter, Fail("insert").Table("users").Error(cerr).Nth(2)))
+	)
+
+	adapter.On("Insert", rel.From("users"), mock.Anything, rel.OnConflict{}).Return(1, nil).Twice()
+	adapter.On("Exec", mock.Anything, "SELECT 1", []interface{}(nil)).Return(0, 0, nil).Once()
+
+	assert.Nil(t, repo.Insert(ctx, &User{Name: "Alice"}))
+	assert.Equal(t, cerr, repo.Insert(ctx, &User{Name: "Bob"}))
+	assert.Nil(t, repo.Insert(ctx, &User{Name: "Carol"}))
+
+	_, _, err := repo.Exec(ctx, "SELECT 1")
+	assert.Nil(t, err)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestFaultAdapter_multipleNth(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = rel.New(InjectFaults(adapter, Fail("insert").Nth(1), Fail("insert").Nth(2)))
+	)
+
+	adapter.On("Insert", rel.From("users"), mock.Anything, rel.OnConflict{}).Return(1, nil).Once()
+
+	assert.Equal(t, errors.New("reltest: injected insert fault"), repo.Insert(ctx, &User{Name: "Alice"}))
+	assert.Equal(t, errors.New("reltest: injected insert fault"), repo.Insert(ctx, &User{Name: "Bob"}))
+	assert.Nil(t, repo.Insert(ctx, &User{Name: "Carol"}))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestFaultAdapter_timeout(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = rel.New(InjectFaults(adapter, Fail("query").Timeout()))
+		users   []User
+	)
+
+	assert.Equal(t, context.DeadlineExceeded, repo.FindAll(ctx, &users))
+	adapter.AssertExpectations(t)
+}
+
+func TestFaultAdapter_commit(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = rel.New(InjectFaults(adapter, Fail("commit")))
+	)
+
+	adapter.On("Begin").Return(adapter, nil).Once()
+	adapter.On("Insert", rel.From("users"), mock.Anything, rel.OnConflict{}).Return(1, nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err := repo.Transaction(ctx, func(ctx context.Context) error {
+		return repo.Insert(ctx, &User{Name: "Alice"})
+	})
+
+	assert.Equal(t, errors.New("reltest: injected commit fault"), err)
+	adapter.AssertExpectations(t)
+}
+
+func TestFaultAdapter_scan(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = rel.New(InjectFaults(adapter, Fail("scan").Table("users").Nth(2)))
+		users   []User
+		cursor  = &testadapter.Cursor{
+			Columns: []string{"id", "name"},
+			Rows:    [][]interface{}{{int64(1), "Alice"}, {int64(2), "Bob"}, {int64(3), "Carol"}},
+		}
+	)
+
+	adapter.On("Query", rel.From("users")).Return(cursor, nil).Once()
+
+	assert.Equal(t, errors.New("reltest: injected scan fault"), repo.FindAll(ctx, &users))
+	assert.Equal(t, 2, cursor.Row())
+	adapter.AssertExpectations(t)
+}
+
+func TestFaultAdapter_probability(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = rel.New(InjectFaults(adapter, Fail("exec").Probability(0.5, 1)))
+		failed  int
+	)
+
+	adapter.On("Exec", mock.Anything, "SELECT 1", []interface{}(nil)).Return(0, 0, nil)
+
+	for i := 0; i < 100; i++ {
+		if _, _, err := repo.Exec(ctx, "SELECT 1"); err != nil {
+			failed++
+		}
+	}
+
+	assert.Greater(t, failed, 0)
+	assert.Less(t, failed, 100)
+}
+
+func TestFaultAdapter_forward(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		fa      = InjectFaults(adapter)
+	)
+
+	adapter.On("Ping").Return(nil).Once()
+	adapter.On("Aggregate", rel.From("users"), "count", "*").Return(1, nil).Once()
+	adapter.On("InsertAll", rel.From("users"), []string{"name"}, mock.Anything, rel.OnConflict{}).Return([]interface{}{1}, nil).Once()
+	adapter.On("Update", rel.From("users"), "id", map[string]rel.Mutate(nil)).Return(1, nil).Once()
+	adapter.On("Delete", rel.From("users")).Return(1, nil).Once()
+	adapter.On("Apply", rel.Migration(nil)).Return(nil).Once()
+	adapter.On("Close").Return(nil).Once()
+
+	assert.Nil(t, fa.Ping(ctx))
+
+	count, err := fa.Aggregate(ctx, rel.From("users"), "count", "*")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = fa.InsertAll(ctx, rel.From("users"), "id", []string{"name"}, nil, rel.OnConflict{})
+	assert.Nil(t, err)
+
+	_, err = fa.Update(ctx, rel.From("users"), "id", nil)
+	assert.Nil(t, err)
+
+	_, err = fa.Delete(ctx, rel.From("users"))
+	assert.Nil(t, err)
+
+	assert.Nil(t, fa.Apply(ctx, nil))
+	assert.Nil(t, fa.Close())
+
+	adapter.AssertExpectations(t)
+}
+
+func TestFaultAdapter_transactionOptionsNotSupported(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		repo    = rel.New(InjectFaults(adapter))
+	)
+
+	err := repo.Transaction(ctx, func(ctx context.Context) error {
+		return nil
+	}, rel.ReadOnly(true))
+
+	assert.Equal(t, rel.ErrUnsupportedTransactionOptions, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestFaultAdapter_savepointNotSupported(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		adapter = &testadapter.Adapter{}
+		fa      = InjectFaults(adapter, Fail("release"))
+	)
+
+	assert.Equal(t, rel.ErrUnsupportedSavepoint, fa.Savepoint(ctx, "step"))
+	assert.Equal(t, rel.ErrUnsupportedSavepoint, fa.RollbackTo(ctx, "step"))
+	assert.Equal(t, errors.New("reltest: injected release fault"), fa.Release(ctx, "step"))
+	adapter.AssertExpectations(t)
+}
@@ -0,0 +1,683 @@
+package reltest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-rel/rel"
+)
+
+// Call to adapter stored in golden file.
+// Op, Query and Args are used to match calls when replaying, the rest are results of the call.
+type Call struct {
+	Op     string    `json:"op"`
+	Query  string    `json:"query,omitempty"`
+	Args   []string  `json:"args,omitempty"`
+	Fields []string  `json:"fields,omitempty"`
+	Rows   [][]Value `json:"rows,omitempty"`
+	Result []Value   `json:"result,omitempty"`
+	Error  string    `json:"error,omitempty"`
+	// ErrorKind and its fields are used to rebuild typed error when replaying,
+	// available kinds are: not_found, constraint, retryable, deadline_exceeded and canceled.
+	ErrorKind  string `json:"error_kind,omitempty"`
+	ErrorType  int8   `json:"error_type,omitempty"`
+	ErrorKey   string `json:"error_key,omitempty"`
+	ErrorCause string `json:"error_cause,omitempty"`
+}
+
+func (c Call) lines() []string {
+	lines := []string{"op: " + c.Op}
+	if c.Query != "" {
+		lines = append(lines, "query: "+c.Query)
+	}
+
+	for _, arg := range c.Args {
+		lines = append(lines, "arg: "+arg)
+	}
+
+	return lines
+}
+
+func (c *Call) setErr(err error) {
+	var (
+		nfe rel.NotFoundError
+		ce  rel.ConstraintError
+		re  rel.RetryableError
+	)
+
+	c.Error = err.Error()
+
+	switch {
+	case errors.As(err, &nfe):
+		c.ErrorKind = "not_found"
+	case errors.As(err, &ce):
+		c.ErrorKind, c.ErrorType, c.ErrorKey = "constraint", int8(ce.Type), ce.Key
+		if ce.Err != nil {
+			c.ErrorCause = ce.Err.Error()
+		}
+	case errors.As(err, &re):
+		c.ErrorKind, c.ErrorType = "retryable", int8(re.Type)
+		if re.Err != nil {
+			c.ErrorCause = re.Err.Error()
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		c.ErrorKind = "deadline_exceeded"
+	case errors.Is(err, context.Canceled):
+		c.ErrorKind = "canceled"
+	}
+}
+
+func (c Call) err() error {
+	if c.Error == "" {
+		return nil
+	}
+
+	var (
+		cause error
+	)
+
+	if c.ErrorCause != "" {
+		cause = errors.New(c.ErrorCause)
+	}
+
+	switch c.ErrorKind {
+	case "not_found":
+		return rel.NotFoundError{}
+	case "constraint":
+		return rel.ConstraintError{Key: c.ErrorKey, Type: rel.ConstraintType(c.ErrorType), Err: cause}
+	case "retryable":
+		return rel.RetryableError{Type: rel.RetryableType(c.ErrorType), Err: cause}
+	case "deadline_exceeded":
+		return context.DeadlineExceeded
+	case "canceled":
+		return context.Canceled
+	default:
+		return errors.New(c.Error)
+	}
+}
+
+// Value recorded from database.
+// time.Time and []byte are stored as tagged object to preserve its type.
+type Value struct {
+	V interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v Value) MarshalJSON() ([]byte, error) {
+	switch x := v.V.(type) {
+	case time.Time:
+		return json.Marshal(map[string]string{"time": x.Format(time.RFC3339Nano)})
+	case []byte:
+		if utf8.Valid(x) {
+			return json.Marshal(map[string]string{"bytes": string(x)})
+		}
+
+		return json.Marshal(map[string][]byte{"base64": x})
+	default:
+		return json.Marshal(x)
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var (
+		tagged  map[string]string
+		decoder = json.NewDecoder(strings.NewReader(string(data)))
+	)
+
+	if err := json.Unmarshal(data, &tagged); err == nil && len(tagged) == 1 {
+		switch {
+		case tagged["time"] != "":
+			t, err := time.Parse(time.RFC3339Nano, tagged["time"])
+			v.V = t
+			return err
+		case tagged["base64"] != "":
+			var raw map[string][]byte
+			err := json.Unmarshal(data, &raw)
+			v.V = raw["base64"]
+			return err
+		default:
+			v.V = []byte(tagged["bytes"])
+			return nil
+		}
+	}
+
+	decoder.UseNumber()
+	if err := decoder.Decode(&v.V); err != nil {
+		return err
+	}
+
+	if n, ok := v.V.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			v.V = i
+		} else {
+			v.V, _ = n.Float64()
+		}
+	}
+
+	return nil
+}
+
+func values(vs ...interface{}) []Value {
+	result := make([]Value, len(vs))
+	for i := range vs {
+		result[i] = Value{V: vs[i]}
+	}
+
+	return result
+}
+
+type recording struct {
+	lock     sync.Mutex
+	filename string
+	replay   bool
+	calls    []*Call
+	pos      int
+}
+
+// add call when recording, or returns the matching call when replaying.
+func (r *recording) add(call Call) (*Call, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.replay {
+		r.calls = append(r.calls, &call)
+		return &call, nil
+	}
+
+	if r.pos >= len(r.calls) {
+		return nil, errors.New("reltest: unexpected call #" + strconv.Itoa(r.pos+1) + ":\n" + diff(nil, call.lines()))
+	}
+
+	expected := r.calls[r.pos]
+	if !reflect.DeepEqual(expected.lines(), call.lines()) {
+		return nil, errors.New("reltest: call #" + strconv.Itoa(r.pos+1) + " mismatch:\n" + diff(expected.lines(), call.lines()))
+	}
+
+	r.pos++
+	return expected, nil
+}
+
+func diff(expected []string, actual []string) string {
+	var (
+		buffer strings.Builder
+		n      = len(expected)
+	)
+
+	if len(actual) > n {
+		n = len(actual)
+	}
+
+	for i := 0; i < n; i++ {
+		switch {
+		case i < len(expected) && i < len(actual) && expected[i] == actual[i]:
+			buffer.WriteString("  " + expected[i] + "\n")
+		default:
+			if i < len(expected) {
+				buffer.WriteString("- " + expected[i] + "\n")
+			}
+
+			if i < len(actual) {
+				buffer.WriteString("+ " + actual[i] + "\n")
+			}
+		}
+	}
+
+	return buffer.String()
+}
+
+// Recorder is an adapter that records every call to the wrapped adapter into golden file,
+// or replays calls from golden file without database.
+// Mutation containing current time should use repository created with rel.WithClock returning fixed time
+// to keep recording deterministic.
+type Recorder struct {
+	adapter   rel.Adapter
+	recording *recording
+}
+
+var (
+	_ rel.Adapter             = (*Recorder)(nil)
+	_ rel.TransactionBeginner = (*Recorder)(nil)
+	_ rel.Savepointer         = (*Recorder)(nil)
+)
+
+// Record calls to adapter, golden file is written when recorder is closed.
+func Record(adapter rel.Adapter, filename string) *Recorder {
+	return &Recorder{
+		adapter:   adapter,
+		recording: &recording{filename: filename},
+	}
+}
+
+// Replay calls from golden file.
+// Calls that doesn't match recorded calls returns error describing the difference.
+func Replay(filename string) (*Recorder, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{recording: &recording{filename: filename, replay: true}}
+	if err := json.Unmarshal(data, &r.recording.calls); err != nil {
+		return nil, errors.New("reltest: invalid golden file: " + filename + ": " + err.Error())
+	}
+
+	return r, nil
+}
+
+func (r *Recorder) call(call Call, fn func(call *Call) error) (*Call, error) {
+	recorded, err := r.recording.add(call)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.recording.replay {
+		err := fn(recorded)
+		if err != nil {
+			recorded.setErr(err)
+		}
+
+		return recorded, err
+	}
+
+	return recorded, recorded.err()
+}
+
+// Close writes golden file when recording, or returns error if there are calls that hasn't been replayed.
+func (r *Recorder) Close() error {
+	if r.recording.replay {
+		if r.recording.pos < len(r.recording.calls) {
+			return errors.New("reltest: " + strconv.Itoa(len(r.recording.calls)-r.recording.pos) + " recorded calls were not replayed, next:\n" +
+				diff(r.recording.calls[r.recording.pos].lines(), nil))
+		}
+
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.recording.calls, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(r.recording.filename, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	return r.adapter.Close()
+}
+
+// Instrumentation set instrumenter of the recorded adapter.
+func (r *Recorder) Instrumentation(instrumenter rel.Instrumenter) {
+	if r.adapter != nil {
+		r.adapter.Instrumentation(instrumenter)
+	}
+}
+
+// Ping database.
+func (r *Recorder) Ping(ctx context.Context) error {
+	_, err := r.call(Call{Op: "ping"}, func(call *Call) error {
+		return r.adapter.Ping(ctx)
+	})
+
+	return err
+}
+
+// Aggregate query.
+func (r *Recorder) Aggregate(ctx context.Context, query rel.Query, mode string, field string) (int, error) {
+	call, err := r.call(Call{Op: "aggregate", Query: query.String(), Args: []string{mode, field}}, func(call *Call) error {
+		result, err := r.adapter.Aggregate(ctx, query, mode, field)
+		call.Result = values(result)
+		return err
+	})
+
+	return resultInt(call, 0), err
+}
+
+// Query database.
+func (r *Recorder) Query(ctx context.Context, query rel.Query) (rel.Cursor, error) {
+	var (
+		cursor rel.Cursor
+	)
+
+	call, err := r.call(Call{Op: "query", Query: query.String()}, func(call *Call) error {
+		var err error
+		cursor, err = r.adapter.Query(ctx, query)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &recordCursor{cursor: cursor, call: call, row: -1}, nil
+}
+
+// Insert a record.
+func (r *Recorder) Insert(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate, onConflict rel.OnConflict) (interface{}, error) {
+	args := append([]string{primaryField}, formatMutates(mutates)...)
+	args = append(args, formatOnConflict(onConflict)...)
+
+	call, err := r.call(Call{Op: "insert", Query: query.String(), Args: args}, func(call *Call) error {
+		id, err := r.adapter.Insert(ctx, query, primaryField, mutates, onConflict)
+		call.Result = values(id)
+		return err
+	})
+
+	return resultValue(call, 0), err
+}
+
+// InsertAll records.
+func (r *Recorder) InsertAll(ctx context.Context, query rel.Query, primaryField string, fields []string, bulkMutates []map[string]rel.Mutate, onConflict rel.OnConflict) ([]interface{}, error) {
+	args := []string{primaryField, strings.Join(fields, ", ")}
+	for i := range bulkMutates {
+		args = append(args, strings.Join(formatMutates(bulkMutates[i]), ", "))
+	}
+
+	args = append(args, formatOnConflict(onConflict)...)
+
+	call, err := r.call(Call{Op: "insert-all", Query: query.String(), Args: args}, func(call *Call) error {
+		ids, err := r.adapter.InsertAll(ctx, query, primaryField, fields, bulkMutates, onConflict)
+		call.Result = values(ids...)
+		return err
+	})
+
+	if call == nil {
+		return nil, err
+	}
+
+	ids := make([]interface{}, len(call.Result))
+	for i := range ids {
+		ids[i] = call.Result[i].V
+	}
+
+	return ids, err
+}
+
+// Update records.
+func (r *Recorder) Update(ctx context.Context, query rel.Query, primaryField string, mutates map[string]rel.Mutate) (int, error) {
+	args := append([]string{primaryField}, formatMutates(mutates)...)
+
+	call, err := r.call(Call{Op: "update", Query: query.String(), Args: args}, func(call *Call) error {
+		updatedCount, err := r.adapter.Update(ctx, query, primaryField, mutates)
+		call.Result = values(updatedCount)
+		return err
+	})
+
+	return resultInt(call, 0), err
+}
+
+// Delete records.
+func (r *Recorder) Delete(ctx context.Context, query rel.Query) (int, error) {
+	call, err := r.call(Call{Op: "delete", Query: query.String()}, func(call *Call) error {
+		deletedCount, err := r.adapter.Delete(ctx, query)
+		call.Result = values(deletedCount)
+		return err
+	})
+
+	return resultInt(call, 0), err
+}
+
+// Exec raw statement.
+func (r *Recorder) Exec(ctx context.Context, stmt string, args []interface{}) (int64, int64, error) {
+	call, err := r.call(Call{Op: "exec", Args: []string{stmt, fmt.Sprint(args...)}}, func(call *Call) error {
+		lastInsertedID, rowsAffected, err := r.adapter.Exec(ctx, stmt, args)
+		call.Result = values(lastInsertedID, rowsAffected)
+		return err
+	})
+
+	return int64(resultInt(call, 0)), int64(resultInt(call, 1)), err
+}
+
+// Begin transaction.
+func (r *Recorder) Begin(ctx context.Context) (rel.Adapter, error) {
+	var (
+		trx = &Recorder{recording: r.recording}
+	)
+
+	_, err := r.call(Call{Op: "begin"}, func(call *Call) error {
+		var err error
+		trx.adapter, err = r.adapter.Begin(ctx)
+		return err
+	})
+
+	return trx, err
+}
+
+// BeginTransaction with options.
+func (r *Recorder) BeginTransaction(ctx context.Context, options rel.TransactionOptions) (rel.Adapter, error) {
+	var (
+		trx = &Recorder{recording: r.recording}
+	)
+
+	_, err := r.call(Call{Op: "begin", Args: []string{options.String()}}, func(call *Call) error {
+		beginner, ok := r.adapter.(rel.TransactionBeginner)
+		if !ok {
+			return rel.ErrUnsupportedTransactionOptions
+		}
+
+		var err error
+		trx.adapter, err = beginner.BeginTransaction(ctx, options)
+		return err
+	})
+
+	return trx, err
+}
+
+// Commit transaction.
+func (r *Recorder) Commit(ctx context.Context) error {
+	_, err := r.call(Call{Op: "commit"}, func(call *Call) error {
+		return r.adapter.Commit(ctx)
+	})
+
+	return err
+}
+
+// Rollback transaction.
+func (r *Recorder) Rollback(ctx context.Context) error {
+	_, err := r.call(Call{Op: "rollback"}, func(call *Call) error {
+		return r.adapter.Rollback(ctx)
+	})
+
+	return err
+}
+
+// Savepoint creates a named savepoint.
+func (r *Recorder) Savepoint(ctx context.Context, name string) error {
+	return r.savepoint("savepoint", name, func(savepointer rel.Savepointer) error {
+		return savepointer.Savepoint(ctx, name)
+	})
+}
+
+// RollbackTo a named savepoint.
+func (r *Recorder) RollbackTo(ctx context.Context, name string) error {
+	return r.savepoint("rollback-to", name, func(savepointer rel.Savepointer) error {
+		return savepointer.RollbackTo(ctx, name)
+	})
+}
+
+// Release a named savepoint.
+func (r *Recorder) Release(ctx context.Context, name string) error {
+	return r.savepoint("release", name, func(savepointer rel.Savepointer) error {
+		return savepointer.Release(ctx, name)
+	})
+}
+
+func (r *Recorder) savepoint(op string, name string, fn func(savepointer rel.Savepointer) error) error {
+	_, err := r.call(Call{Op: op, Args: []string{name}}, func(call *Call) error {
+		savepointer, ok := r.adapter.(rel.Savepointer)
+		if !ok {
+			return rel.ErrUnsupportedSavepoint
+		}
+
+		return fn(savepointer)
+	})
+
+	return err
+}
+
+// Apply migration.
+func (r *Recorder) Apply(ctx context.Context, migration rel.Migration) error {
+	detail := rel.Schema{Migrations: []rel.Migration{migration}}.Describe()
+
+	_, err := r.call(Call{Op: "apply", Args: detail}, func(call *Call) error {
+		return r.adapter.Apply(ctx, migration)
+	})
+
+	return err
+}
+
+type recordCursor struct {
+	cursor rel.Cursor
+	call   *Call
+	row    int
+	values []interface{}
+}
+
+func (rc *recordCursor) Close() error {
+	if rc.cursor != nil {
+		return rc.cursor.Close()
+	}
+
+	return nil
+}
+
+func (rc *recordCursor) Fields() ([]string, error) {
+	if rc.cursor != nil {
+		fields, err := rc.cursor.Fields()
+		rc.call.Fields = fields
+		return fields, err
+	}
+
+	return rc.call.Fields, nil
+}
+
+func (rc *recordCursor) Next() bool {
+	rc.row++
+	rc.values = nil
+
+	if rc.cursor != nil {
+		return rc.cursor.Next()
+	}
+
+	return rc.row < len(rc.call.Rows)
+}
+
+// Scan current row, values of the row is scanned once from the recorded cursor
+// so the same row can be scanned multiple times.
+func (rc *recordCursor) Scan(dest ...interface{}) error {
+	if rc.values == nil {
+		if rc.cursor != nil {
+			var (
+				raws  = make([]interface{}, len(dest))
+				ptrs  = make([]interface{}, len(dest))
+				value = make([]Value, len(dest))
+			)
+
+			for i := range raws {
+				ptrs[i] = &raws[i]
+			}
+
+			if err := rc.cursor.Scan(ptrs...); err != nil {
+				return err
+			}
+
+			for i := range raws {
+				value[i] = Value{V: raws[i]}
+			}
+
+			rc.call.Rows = append(rc.call.Rows, value)
+			rc.row = len(rc.call.Rows) - 1
+		}
+
+		row := rc.call.Rows[rc.row]
+		rc.values = make([]interface{}, len(row))
+		for i := range row {
+			rc.values[i] = row[i].V
+		}
+	}
+
+	if len(dest) != len(rc.values) {
+		return errors.New("reltest: expected " + strconv.Itoa(len(rc.values)) + " destination arguments in Scan, not " + strconv.Itoa(len(dest)))
+	}
+
+	for i := range dest {
+		if err := assign(dest[i], rc.values[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (rc *recordCursor) NopScanner() interface{} {
+	return &sql.RawBytes{}
+}
+
+// assign value to scan destination using the same conversion as rel.Nullable.
+func assign(dest interface{}, value interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Ptr {
+		if value == nil {
+			rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+			return nil
+		}
+
+		ptr := reflect.New(rv.Elem().Type().Elem())
+		if err := rel.Nullable(ptr.Interface()).(sql.Scanner).Scan(value); err != nil {
+			return err
+		}
+
+		rv.Elem().Set(ptr)
+		return nil
+	}
+
+	return rel.Nullable(dest).(sql.Scanner).Scan(value)
+}
+
+func formatMutates(mutates map[string]rel.Mutate) []string {
+	result := make([]string, 0, len(mutates))
+	for _, mutate := range mutates {
+		result = append(result, mutate.String())
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+func formatOnConflict(onConflict rel.OnConflict) []string {
+	if reflect.DeepEqual(onConflict, rel.OnConflict{}) {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("on conflict %+v", onConflict)}
+}
+
+func resultValue(call *Call, i int) interface{} {
+	if call == nil || i >= len(call.Result) {
+		return nil
+	}
+
+	return call.Result[i].V
+}
+
+func resultInt(call *Call, i int) int {
+	switch v := resultValue(call, i).(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	}
+
+	return 0
+}
@@ -0,0 +1,180 @@
+package reltest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/internal/testadapter"
+	"github.com/go-rel/rel/where"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type User struct {
+	ID        int
+	Name      string
+	Avatar    *string
+	CreatedAt time.Time
+}
+
+func runRecorderScenario(t *testing.T, repo rel.Repository) {
+	var (
+		ctx   = context.TODO()
+		users []User
+		user  = User{Name: "Bob"}
+	)
+
+	assert.Nil(t, repo.FindAll(ctx, &users, where.Eq("name", "Alice")))
+	for i := range users {
+		// rel converts time to local when offset is equal.
+		users[i].CreatedAt = users[i].CreatedAt.UTC()
+	}
+
+	assert.Equal(t, []User{
+		{ID: 1, Name: "Alice", CreatedAt: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{ID: 2, Name: "Alice", Avatar: &[]string{"alice.png"}[0], CreatedAt: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}, users)
+
+	assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+		return repo.Insert(ctx, &user)
+	}))
+	assert.Equal(t, 3, user.ID)
+
+	_, err := repo.DeleteAny(ctx, rel.From("users").Where(where.Eq("id", 4)))
+	assert.Equal(t, errors.New("not found"), err)
+}
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reltest")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		now      = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+		filename = filepath.Join(dir, "users.golden.json")
+		adapter  = &testadapter.Adapter{}
+		cursor   = &testadapter.Cursor{
+			Columns: []string{"id", "name", "avatar", "created_at"},
+			Rows: [][]interface{}{
+				{int64(1), []byte("Alice"), nil, now},
+				{int64(2), "Alice", []byte("alice.png"), now},
+			},
+		}
+	)
+
+	clock := rel.WithClock(func() time.Time { return now })
+
+	adapter.On("Query", rel.From("users").Where(where.Eq("name", "Alice"))).Return(cursor, nil).Once()
+	adapter.On("Begin").Return(adapter, nil).Once()
+	adapter.On("Insert", rel.From("users"), mock.Anything, rel.OnConflict{}).Return(3, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+	adapter.On("Delete", rel.From("users").Where(where.Eq("id", 4))).Return(0, errors.New("not found")).Once()
+	adapter.On("Close").Return(nil).Once()
+
+	recorder := Record(adapter, filename)
+	runRecorderScenario(t, rel.New(recorder, clock))
+	assert.Nil(t, recorder.Close())
+	adapter.AssertExpectations(t)
+
+	replayer, err := Replay(filename)
+	assert.Nil(t, err)
+	runRecorderScenario(t, rel.New(replayer, clock))
+	assert.Nil(t, replayer.Close())
+}
+
+func TestRecorder_typedError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reltest")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		ctx      = context.TODO()
+		filename = filepath.Join(dir, "errors.golden.json")
+		adapter  = &testadapter.Adapter{}
+		cerr     = rel.ConstraintError{Key: "users_name_key", Type: rel.UniqueConstraint, Err: errors.New("duplicate key")}
+		rerr     = rel.RetryableError{Type: rel.Deadlock, Err: errors.New("deadlock detected")}
+	)
+
+	adapter.On("Delete", rel.From("users")).Return(0, cerr).Once()
+	adapter.On("Exec", mock.Anything, "UPDATE users", []interface{}(nil)).Return(0, 0, rerr).Once()
+	adapter.On("Aggregate", rel.From("users"), "count", "*").Return(0, context.DeadlineExceeded).Once()
+	adapter.On("Close").Return(nil).Once()
+
+	scenario := func(repo rel.Repository) {
+		_, err := repo.DeleteAny(ctx, rel.From("users"))
+		assert.Equal(t, cerr, err)
+		assert.True(t, errors.Is(err, rel.ErrUniqueConstraint))
+
+		_, _, err = repo.Exec(ctx, "UPDATE users")
+		assert.Equal(t, rerr, err)
+		assert.True(t, errors.Is(err, rel.ErrDeadlock))
+
+		_, err = repo.Count(ctx, "users")
+		assert.Equal(t, context.DeadlineExceeded, err)
+	}
+
+	recorder := Record(adapter, filename)
+	scenario(rel.New(recorder))
+	assert.Nil(t, recorder.Close())
+	adapter.AssertExpectations(t)
+
+	replayer, err := Replay(filename)
+	assert.Nil(t, err)
+	scenario(rel.New(replayer))
+	assert.Nil(t, replayer.Close())
+}
+
+func TestReplay_mismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reltest")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		ctx      = context.TODO()
+		filename = filepath.Join(dir, "delete.golden.json")
+		adapter  = &testadapter.Adapter{}
+		recorder = Record(adapter, filename)
+	)
+
+	adapter.On("Delete", rel.From("users").Where(where.Eq("id", 1))).Return(1, nil).Once()
+	adapter.On("Exec", mock.Anything, "TRUNCATE users", []interface{}(nil)).Return(0, 0, nil).Once()
+	adapter.On("Close").Return(nil).Once()
+
+	repo := rel.New(recorder)
+	repo.MustDeleteAny(ctx, rel.From("users").Where(where.Eq("id", 1)))
+	repo.MustExec(ctx, "TRUNCATE users")
+	assert.Nil(t, recorder.Close())
+
+	replayer, err := Replay(filename)
+	assert.Nil(t, err)
+
+	repo = rel.New(replayer)
+	_, err = repo.DeleteAny(ctx, rel.From("users").Where(where.Eq("id", 2)))
+	assert.Equal(t, errors.New("reltest: call #1 mismatch:\n"+
+		"  op: delete\n"+
+		"- query: rel.From(\"users\").Where(where.Eq(\"id\", 1))\n"+
+		"+ query: rel.From(\"users\").Where(where.Eq(\"id\", 2))\n"), err)
+
+	assert.Equal(t, errors.New("reltest: 2 recorded calls were not replayed, next:\n- op: delete\n- query: rel.From(\"users\").Where(where.Eq(\"id\", 1))\n"), replayer.Close())
+
+	repo.MustDeleteAny(ctx, rel.From("users").Where(where.Eq("id", 1)))
+	repo.MustExec(ctx, "TRUNCATE users")
+	assert.Nil(t, replayer.Close())
+
+	_, _, err = repo.Exec(ctx, "TRUNCATE users")
+	assert.Equal(t, errors.New("reltest: unexpected call #3:\n+ op: exec\n+ arg: TRUNCATE users\n+ arg: \n"), err)
+}
+
+func TestReplay_invalidFile(t *testing.T) {
+	_, err := Replay("testdata/missing.json")
+	assert.NotNil(t, err)
+
+	_, err = Replay("recorder.go")
+	assert.Contains(t, err.Error(), "reltest: invalid golden file: recorder.go")
+}
@@ -0,0 +1,49 @@
+// Package reltest provides helpers for running integration tests against real database.
+package reltest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+)
+
+// WithRollback runs fn inside a transaction that's always rolled back after fn returns,
+// so every test can be isolated without truncating tables.
+// Context passed to fn carries the transaction adapter,
+// nested Transaction calls inside fn are handled by the adapter using savepoints,
+// and callbacks registered using rel.AfterCommit inside fn are discarded since nothing is committed.
+func WithRollback(t testing.TB, repo rel.Repository, fn func(ctx context.Context)) {
+	t.Helper()
+
+	ctx, rollback := begin(t, repo)
+	defer rollback()
+
+	fn(ctx)
+}
+
+// RollbackContext returns context that carries transaction adapter,
+// the transaction is rolled back when the test and all its subtests complete.
+func RollbackContext(t testing.TB, repo rel.Repository) context.Context {
+	t.Helper()
+
+	ctx, rollback := begin(t, repo)
+	t.Cleanup(rollback)
+
+	return ctx
+}
+
+func begin(t testing.TB, repo rel.Repository) (context.Context, func()) {
+	ctx := context.Background()
+
+	adapter, err := repo.Adapter(ctx).Begin(ctx)
+	if err != nil {
+		t.Fatalf("reltest: failed to begin transaction: %v", err)
+	}
+
+	return rel.WithAdapter(ctx, adapter), func() {
+		if err := adapter.Rollback(ctx); err != nil {
+			t.Errorf("reltest: failed to rollback transaction: %v", err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package reltest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/go-rel/rel/internal/testadapter"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeT struct {
+	testing.TB
+	fatal   string
+	errors  []string
+	cleanup []func()
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	f.fatal = format
+	panic("fatal")
+}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, format)
+}
+
+func (f *fakeT) Cleanup(fn func()) {
+	f.cleanup = append(f.cleanup, fn)
+}
+
+func TestWithRollback(t *testing.T) {
+	var (
+		adapter    = &testadapter.Adapter{}
+		trxAdapter = &testadapter.Adapter{}
+		repo       = rel.New(adapter)
+		called     = false
+		committed  = false
+	)
+
+	adapter.On("Begin").Return(trxAdapter, nil).Once()
+	trxAdapter.On("Delete", rel.From("users")).Return(1, nil).Once()
+	trxAdapter.On("Begin").Return(trxAdapter, nil).Once()
+	trxAdapter.On("Delete", rel.From("addresses")).Return(1, nil).Once()
+	trxAdapter.On("Commit").Return(nil).Once()
+	trxAdapter.On("Rollback").Return(nil).Once()
+
+	WithRollback(t, repo, func(ctx context.Context) {
+		called = true
+		repo.MustDeleteAny(ctx, rel.From("users"))
+
+		assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+			repo.MustDeleteAny(ctx, rel.From("addresses"))
+			rel.AfterCommit(ctx, func(ctx context.Context) {
+				committed = true
+			})
+			return nil
+		}))
+	})
+
+	assert.True(t, called)
+	assert.False(t, committed)
+	adapter.AssertExpectations(t)
+	trxAdapter.AssertExpectations(t)
+}
+
+func TestWithRollback_panic(t *testing.T) {
+	var (
+		adapter    = &testadapter.Adapter{}
+		trxAdapter = &testadapter.Adapter{}
+		repo       = rel.New(adapter)
+	)
+
+	adapter.On("Begin").Return(trxAdapter, nil).Once()
+	trxAdapter.On("Rollback").Return(nil).Once()
+
+	assert.Panics(t, func() {
+		WithRollback(t, repo, func(ctx context.Context) {
+			panic("error")
+		})
+	})
+
+	adapter.AssertExpectations(t)
+	trxAdapter.AssertExpectations(t)
+}
+
+func TestWithRollback_beginError(t *testing.T) {
+	var (
+		ft      = &fakeT{}
+		adapter = &testadapter.Adapter{}
+		repo    = rel.New(adapter)
+	)
+
+	adapter.On("Begin").Return(adapter, errors.New("error")).Once()
+
+	assert.Panics(t, func() {
+		WithRollback(ft, repo, func(ctx context.Context) {
+			t.Fatal("should not be called")
+		})
+	})
+
+	assert.Equal(t, "reltest: failed to begin transaction: %v", ft.fatal)
+	adapter.AssertExpectations(t)
+}
+
+func TestRollbackContext(t *testing.T) {
+	var (
+		ft         = &fakeT{}
+		adapter    = &testadapter.Adapter{}
+		trxAdapter = &testadapter.Adapter{}
+		repo       = rel.New(adapter)
+	)
+
+	adapter.On("Begin").Return(trxAdapter, nil).Once()
+	trxAdapter.On("Delete", rel.From("users")).Return(1, nil).Once()
+	trxAdapter.On("Rollback").Return(errors.New("error")).Once()
+
+	ctx := RollbackContext(ft, repo)
+	repo.MustDeleteAny(ctx, rel.From("users"))
+
+	assert.Len(t, ft.cleanup, 1)
+	ft.cleanup[0]()
+
+	assert.Equal(t, []string{"reltest: failed to rollback transaction: %v"}, ft.errors)
+	adapter.AssertExpectations(t)
+	trxAdapter.AssertExpectations(t)
+}
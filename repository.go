@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"time"
 )
 
 // Repository for interacting with database.
@@ -104,6 +105,9 @@ type Repository interface {
 	MustUpdateAny(ctx context.Context, query Query, mutates ...Mutate) int
 
 	// Delete a record.
+	// Record that has deleted or deleted_at field is soft deleted, unless ForceDelete(true) is used.
+	// With Cascade(true), each loaded association is soft deleted using the same time if it supports soft delete,
+	// otherwise it's deleted permanently.
 	Delete(ctx context.Context, record interface{}, mutators ...Mutator) error
 
 	// MustDelete a record.
@@ -128,12 +132,49 @@ type Repository interface {
 	// Returns number of updated records.
 	MustDeleteAny(ctx context.Context, query Query) int
 
+	// Restore a soft deleted record.
+	// Returns ErrNotSoftDeletable if record doesn't have deleted or deleted_at field.
+	// With Cascade(true), loaded associations that were deleted at the same time as the record are restored as well.
+	// Deleted row of Historied record is copied to versions table, so AsOf between delete and restore returns ErrNotFound.
+	Restore(ctx context.Context, record interface{}, mutators ...Mutator) error
+
+	// MustRestore a soft deleted record.
+	// It'll panic if any error occurred.
+	MustRestore(ctx context.Context, record interface{}, mutators ...Mutator)
+
+	// RestoreAny soft deleted records that match the queriers.
+	// Records is a pointer to slice that is only used to determine table and soft delete fields.
+	// Versions of Historied records are not recorded, use Restore instead.
+	// Returns number of restored records and error.
+	RestoreAny(ctx context.Context, records interface{}, queriers ...Querier) (int, error)
+
+	// MustRestoreAny soft deleted records that match the queriers.
+	// It'll panic if any error occurred.
+	// Returns number of restored records.
+	MustRestoreAny(ctx context.Context, records interface{}, queriers ...Querier) int
+
 	// Preload association with given query.
 	// This function can accepts either a struct or a slice of structs.
 	// If association is already loaded, this will do nothing.
 	// To force preloading even though association is already loaeded, add `Reload(true)` as query.
 	Preload(ctx context.Context, records interface{}, field string, queriers ...Querier) error
 
+	// Versions of a record stored in versions table, ordered from the oldest.
+	// Record must embed Historied to have its versions recorded.
+	Versions(ctx context.Context, record interface{}) ([]Version, error)
+
+	// MustVersions of a record stored in versions table, ordered from the oldest.
+	// It'll panic if any error occurred.
+	MustVersions(ctx context.Context, record interface{}) []Version
+
+	// AsOf loads state of a record at the given time.
+	// Record is loaded from versions table when it's changed after the given time, otherwise current row is used.
+	AsOf(ctx context.Context, record interface{}, t time.Time) error
+
+	// MustAsOf loads state of a record at the given time.
+	// It'll panic if any error occurred.
+	MustAsOf(ctx context.Context, record interface{}, t time.Time)
+
 	// MustPreload association with given query.
 	// This function can accepts either a struct or a slice of structs.
 	// It'll panic if any error occurred.
@@ -149,12 +190,32 @@ type Repository interface {
 
 	// Transaction performs transaction with given function argument.
 	// Transaction scope/connection is automatically passed using context.
-	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// Options such as isolation level requires adapter to implement TransactionBeginner.
+	// Nested transaction inherits options of the outer transaction,
+	// and returns ErrNestedTransactionOptions when it's given different options.
+	Transaction(ctx context.Context, fn func(ctx context.Context) error, options ...TransactionOption) error
+
+	// Savepoint performs function inside a named savepoint of the current transaction.
+	// Changes made by the function are rolled back to the savepoint when it returns error or panics,
+	// without aborting the transaction.
+	// AfterCommit callbacks registered inside a rolled back savepoint are discarded.
+	// Requires adapter to implement Savepointer.
+	Savepoint(ctx context.Context, name string, fn func(ctx context.Context) error) error
 }
 
 type repository struct {
 	rootAdapter  Adapter
 	instrumenter Instrumenter
+	clock        NowFunc
+}
+
+// Now returns current time using repository clock.
+func (r repository) Now() time.Time {
+	if r.clock != nil {
+		return r.clock()
+	}
+
+	return Now()
 }
 
 func (r repository) Adapter(ctx context.Context) Adapter {
@@ -340,7 +401,7 @@ func (r repository) Insert(ctx context.Context, record interface{}, mutators ...
 	var (
 		cw       = fetchContext(ctx, r.rootAdapter)
 		doc      = NewDocument(record)
-		mutation = Apply(doc, mutators...)
+		mutation = applyMutators(doc, r.clock, true, true, mutators...)
 	)
 
 	if !mutation.IsAssocEmpty() && mutation.Cascade == true {
@@ -414,9 +475,9 @@ func (r repository) InsertAll(ctx context.Context, records interface{}, mutators
 		doc := col.Get(i)
 		if i == 0 {
 			// only need to apply options from first one
-			muts[i] = Apply(doc, mutators...)
+			muts[i] = applyMutators(doc, r.clock, true, true, mutators...)
 		} else {
-			muts[i] = Apply(doc)
+			muts[i] = applyMutators(doc, r.clock, true, true)
 		}
 	}
 
@@ -485,10 +546,10 @@ func (r repository) Update(ctx context.Context, record interface{}, mutators ...
 		cw       = fetchContext(ctx, r.rootAdapter)
 		doc      = NewDocument(record)
 		filter   = filterDocument(doc)
-		mutation = Apply(doc, mutators...)
+		mutation = applyMutators(doc, r.clock, true, true, mutators...)
 	)
 
-	if !mutation.IsAssocEmpty() && mutation.Cascade == true {
+	if (!mutation.IsAssocEmpty() && mutation.Cascade == true) || (!mutation.IsMutatesEmpty() && doc.Flag(HasHistoryTable)) {
 		return r.transaction(cw, func(cw contextWrapper) error {
 			return r.update(cw, doc, mutation, filter)
 		})
@@ -517,6 +578,12 @@ func (r repository) update(cw contextWrapper, doc *Document, mutation Mutation,
 	}
 
 	if !mutation.IsMutatesEmpty() {
+		if doc.Flag(HasHistoryTable) {
+			if err := r.saveVersion(cw, doc, filter); err != nil {
+				return err
+			}
+		}
+
 		if err := r.applyMutates(cw, doc, mutation, filter); err != nil {
 			return err
 		}
@@ -701,12 +768,12 @@ func (r repository) saveHasMany(cw contextWrapper, doc *Document, mutation *Muta
 
 			if deletedIDs == nil {
 				// if it's nil, then clear old association (used by structset).
-				if _, err := r.deleteAny(cw, col.data.flag, Build(table, filter)); err != nil {
+				if _, err := r.deleteAny(cw, col.data, Build(table, filter), r.Now()); err != nil {
 					return err
 				}
 			} else if len(deletedIDs) > 0 {
 				filter = filter.AndIn(col.PrimaryField(), deletedIDs...)
-				if _, err := r.deleteAny(cw, col.data.flag, Build(table, filter)); err != nil {
+				if _, err := r.deleteAny(cw, col.data, Build(table, filter), r.Now()); err != nil {
 					return err
 				}
 			}
@@ -807,19 +874,21 @@ func (r repository) Delete(ctx context.Context, record interface{}, mutators ...
 	var (
 		cw       = fetchContext(ctx, r.rootAdapter)
 		doc      = NewDocument(record)
-		mutation = applyMutators(nil, false, false, mutators...)
+		mutation = applyMutators(nil, r.clock, false, false, mutators...)
 	)
 
-	if mutation.Cascade {
+	if bool(mutation.Cascade) || doc.Flag(HasHistoryTable) {
 		return r.transaction(cw, func(cw contextWrapper) error {
-			return r.delete(cw, doc, filterDocument(doc), mutation)
+			return r.delete(cw, doc, filterDocument(doc), mutation, r.Now())
 		})
 	}
 
-	return r.delete(cw, doc, filterDocument(doc), mutation)
+	return r.delete(cw, doc, filterDocument(doc), mutation, r.Now())
 }
 
-func (r repository) delete(cw contextWrapper, doc *Document, filter FilterQuery, mutation Mutation) error {
+// delete a record, cascaded associations are deleted using the same mutation and time.
+// Each association follows its own soft delete fields, unless ForceDelete is used.
+func (r repository) delete(cw contextWrapper, doc *Document, filter FilterQuery, mutation Mutation, now time.Time) error {
 	var filters []Querier = []Querier{filter, mutation.Unscoped}
 
 	if version, ok := r.lockVersion(*doc, mutation.Unscoped); ok {
@@ -832,22 +901,37 @@ func (r repository) delete(cw contextWrapper, doc *Document, filter FilterQuery,
 	)
 
 	if mutation.Cascade {
-		if err := r.deleteHasOne(cw, doc, true); err != nil {
+		if err := r.deleteHasOne(cw, doc, mutation, now); err != nil {
+			return err
+		}
+
+		if err := r.deleteHasMany(cw, doc, mutation, now); err != nil {
 			return err
 		}
+	}
 
-		if err := r.deleteHasMany(cw, doc); err != nil {
+	if doc.Flag(HasHistoryTable) {
+		if err := r.saveVersion(cw, doc, filter); err != nil {
 			return err
 		}
 	}
 
-	deletedCount, err := r.deleteAny(cw, doc.data.flag, query)
+	data := doc.data
+	if mutation.ForceDelete {
+		data.flag = Invalid
+	}
+
+	deletedCount, err := r.deleteAny(cw, data, query, now)
 	if err == nil && deletedCount == 0 {
 		err = NotFoundError{}
 	}
 
+	if err == nil {
+		markDeleted(cw, doc, data, now)
+	}
+
 	if err == nil && mutation.Cascade {
-		if err := r.deleteBelongsTo(cw, doc, true); err != nil {
+		if err := r.deleteBelongsTo(cw, doc, mutation, now); err != nil {
 			return err
 		}
 	}
@@ -855,7 +939,7 @@ func (r repository) delete(cw contextWrapper, doc *Document, filter FilterQuery,
 	return err
 }
 
-func (r repository) deleteBelongsTo(cw contextWrapper, doc *Document, cascade Cascade) error {
+func (r repository) deleteBelongsTo(cw contextWrapper, doc *Document, mutation Mutation, now time.Time) error {
 	for _, field := range doc.BelongsTo() {
 		var (
 			assoc = doc.Association(field)
@@ -871,7 +955,7 @@ func (r repository) deleteBelongsTo(cw contextWrapper, doc *Document, cascade Ca
 				return err
 			}
 
-			if err := r.delete(cw, assocDoc, filter, Mutation{Cascade: cascade}); err != nil {
+			if err := r.delete(cw, assocDoc, filter, cascadeMutation(mutation), now); err != nil {
 				return err
 			}
 		}
@@ -880,7 +964,7 @@ func (r repository) deleteBelongsTo(cw contextWrapper, doc *Document, cascade Ca
 	return nil
 }
 
-func (r repository) deleteHasOne(cw contextWrapper, doc *Document, cascade Cascade) error {
+func (r repository) deleteHasOne(cw contextWrapper, doc *Document, mutation Mutation, now time.Time) error {
 	for _, field := range doc.HasOne() {
 		var (
 			assoc = doc.Association(field)
@@ -896,7 +980,7 @@ func (r repository) deleteHasOne(cw contextWrapper, doc *Document, cascade Casca
 				return err
 			}
 
-			if err := r.delete(cw, assocDoc, filter, Mutation{Cascade: cascade}); err != nil {
+			if err := r.delete(cw, assocDoc, filter, cascadeMutation(mutation), now); err != nil {
 				return err
 			}
 		}
@@ -905,7 +989,7 @@ func (r repository) deleteHasOne(cw contextWrapper, doc *Document, cascade Casca
 	return nil
 }
 
-func (r repository) deleteHasMany(cw contextWrapper, doc *Document) error {
+func (r repository) deleteHasMany(cw contextWrapper, doc *Document, mutation Mutation, now time.Time) error {
 	for _, field := range doc.HasMany() {
 		var (
 			assoc = doc.Association(field)
@@ -921,17 +1005,49 @@ func (r repository) deleteHasMany(cw contextWrapper, doc *Document) error {
 				fField = assoc.ForeignField()
 				rValue = assoc.ReferenceValue()
 				filter = Eq(fField, rValue).And(filterCollection(col))
+				data   = col.data
 			)
 
-			if _, err := r.deleteAny(cw, col.data.flag, Build(table, filter)); err != nil {
+			if mutation.ForceDelete {
+				data.flag = Invalid
+			}
+
+			if _, err := r.deleteAny(cw, data, Build(table, filter), now); err != nil {
 				return err
 			}
+
+			for i := 0; i < col.Len(); i++ {
+				markDeleted(cw, col.Get(i), data, now)
+			}
 		}
 	}
 
 	return nil
 }
 
+// cascadeMutation used to delete or restore associations.
+func cascadeMutation(mutation Mutation) Mutation {
+	return Mutation{
+		Cascade:     mutation.Cascade,
+		ForceDelete: mutation.ForceDelete,
+	}
+}
+
+// markDeleted sets soft delete fields of the document after it's soft deleted.
+func markDeleted(cw contextWrapper, doc *Document, data documentData, now time.Time) {
+	if data.flag.Is(HasDeletedAt) {
+		doc.SetValue("deleted_at", now)
+	}
+
+	if data.flag.Is(HasDeleted) {
+		doc.SetValue("deleted", true)
+	}
+
+	if deletedBy := cw.ctx.Value(deletedByCtxKey); deletedBy != nil && isSoftDeletable(data) {
+		doc.SetValue("deleted_by", deletedBy)
+	}
+}
+
 func (r repository) MustDelete(ctx context.Context, record interface{}, mutators ...Mutator) {
 	must(r.Delete(ctx, record, mutators...))
 }
@@ -951,7 +1067,7 @@ func (r repository) DeleteAll(ctx context.Context, records interface{}) error {
 
 	var (
 		query  = Build(col.Table(), filterCollection(col))
-		_, err = r.deleteAny(cw, col.data.flag, query)
+		_, err = r.deleteAny(cw, col.data, query, r.Now())
 	)
 
 	return err
@@ -969,7 +1085,7 @@ func (r repository) DeleteAny(ctx context.Context, query Query) (int, error) {
 		cw = fetchContext(ctx, r.rootAdapter)
 	)
 
-	return r.deleteAny(cw, Invalid, query)
+	return r.deleteAny(cw, documentData{flag: Invalid}, query, r.Now())
 }
 
 func (r repository) MustDeleteAny(ctx context.Context, query Query) int {
@@ -978,29 +1094,366 @@ func (r repository) MustDeleteAny(ctx context.Context, query Query) int {
 	return deletedCount
 }
 
-func (r repository) deleteAny(cw contextWrapper, flag DocumentFlag, query Query) (int, error) {
+func (r repository) deleteAny(cw contextWrapper, data documentData, query Query, now time.Time) (int, error) {
+	flag := data.flag
 	hasDeletedAt := flag.Is(HasDeletedAt)
 	hasDeleted := flag.Is(HasDeleted)
 	mutates := make(map[string]Mutate, 1)
 	if hasDeletedAt {
-		mutates["deleted_at"] = Set("deleted_at", Now())
+		mutates["deleted_at"] = Set("deleted_at", now)
 	}
 	if hasDeleted {
 		mutates["deleted"] = Set("deleted", true)
 		if flag.Is(HasUpdatedAt) && !hasDeletedAt {
-			mutates["updated_at"] = Set("updated_at", Now())
+			mutates[data.updatedAt] = Set(data.updatedAt, now)
 		}
 	}
 	if hasDeletedAt || hasDeleted {
 		if flag.Is(HasVersioning) {
 			mutates["lock_version"] = Inc("lock_version")
 		}
+		if deletedBy := cw.ctx.Value(deletedByCtxKey); deletedBy != nil && data.hasField("deleted_by") {
+			mutates["deleted_by"] = Set("deleted_by", deletedBy)
+		}
 		return cw.adapter.Update(cw.ctx, query, "", mutates)
 	}
 
 	return cw.adapter.Delete(cw.ctx, query)
 }
 
+func (r repository) Restore(ctx context.Context, record interface{}, mutators ...Mutator) error {
+	finish := r.instrumenter.Observe(ctx, "rel-restore", "restoring a record")
+	defer finish(nil)
+
+	var (
+		cw       = fetchContext(ctx, r.rootAdapter)
+		doc      = NewDocument(record)
+		mutation = applyMutators(nil, r.clock, false, false, mutators...)
+	)
+
+	if bool(mutation.Cascade) || doc.Flag(HasHistoryTable) {
+		return r.transaction(cw, func(cw contextWrapper) error {
+			return r.restore(cw, doc, filterDocument(doc), mutation)
+		})
+	}
+
+	return r.restore(cw, doc, filterDocument(doc), mutation)
+}
+
+func (r repository) MustRestore(ctx context.Context, record interface{}, mutators ...Mutator) {
+	must(r.Restore(ctx, record, mutators...))
+}
+
+// restore a record, cascaded associations are only restored when they're deleted at the same time as the record,
+// within a second to tolerate precision of the database column.
+// Associations that doesn't support soft delete are skipped.
+func (r repository) restore(cw contextWrapper, doc *Document, filter FilterQuery, mutation Mutation) error {
+	var (
+		deletedAt = documentTime(doc, "deleted_at")
+	)
+
+	if doc.Flag(HasHistoryTable) && isSoftDeletable(doc.data) {
+		if err := r.saveVersion(cw, doc, filter); err != nil {
+			return err
+		}
+	}
+
+	restoredCount, err := r.restoreAny(cw, doc.data, Build(doc.Table(), filter, Unscoped(true)))
+	if err != nil {
+		return err
+	} else if restoredCount == 0 {
+		return NotFoundError{}
+	}
+
+	markRestored(doc)
+
+	if mutation.Cascade {
+		if err := r.restoreHasOne(cw, doc, mutation, deletedAt); err != nil {
+			return err
+		}
+
+		if err := r.restoreHasMany(cw, doc, deletedAt); err != nil {
+			return err
+		}
+
+		if err := r.restoreBelongsTo(cw, doc, mutation, deletedAt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r repository) restoreBelongsTo(cw contextWrapper, doc *Document, mutation Mutation, deletedAt time.Time) error {
+	for _, field := range doc.BelongsTo() {
+		var (
+			assoc = doc.Association(field)
+		)
+
+		if !assoc.Autosave() {
+			continue
+		}
+
+		if assocDoc, loaded := assoc.Document(); loaded && isSoftDeletable(assocDoc.data) {
+			filter, err := filterBelongsTo(assoc)
+			if err != nil {
+				return err
+			}
+
+			filter = filterDeletedAt(filter, assocDoc.data, deletedAt)
+			if err := r.restore(cw, assocDoc, filter, cascadeMutation(mutation)); err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r repository) restoreHasOne(cw contextWrapper, doc *Document, mutation Mutation, deletedAt time.Time) error {
+	for _, field := range doc.HasOne() {
+		var (
+			assoc = doc.Association(field)
+		)
+
+		if !assoc.Autosave() {
+			continue
+		}
+
+		if assocDoc, loaded := assoc.Document(); loaded && isSoftDeletable(assocDoc.data) {
+			filter, err := filterHasOne(assoc, assocDoc)
+			if err != nil {
+				return err
+			}
+
+			filter = filterDeletedAt(filter, assocDoc.data, deletedAt)
+			if err := r.restore(cw, assocDoc, filter, cascadeMutation(mutation)); err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r repository) restoreHasMany(cw contextWrapper, doc *Document, deletedAt time.Time) error {
+	for _, field := range doc.HasMany() {
+		var (
+			assoc = doc.Association(field)
+		)
+
+		if !assoc.Autosave() {
+			continue
+		}
+
+		if col, loaded := assoc.Collection(); loaded && col.Len() != 0 && isSoftDeletable(col.data) {
+			var (
+				table  = col.Table()
+				fField = assoc.ForeignField()
+				rValue = assoc.ReferenceValue()
+				filter = filterDeletedAt(Eq(fField, rValue).And(filterCollection(col)), col.data, deletedAt)
+			)
+
+			if _, err := r.restoreAny(cw, col.data, Build(table, filter, Unscoped(true))); err != nil {
+				return err
+			}
+
+			for i := 0; i < col.Len(); i++ {
+				if assocDoc := col.Get(i); deletedAt.IsZero() || !col.data.flag.Is(HasDeletedAt) || deletedTogether(documentTime(assocDoc, "deleted_at"), deletedAt) {
+					markRestored(assocDoc)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// markRestored clears soft delete fields of the document after it's restored.
+func markRestored(doc *Document) {
+	doc.SetValue("deleted_at", nil)
+	doc.SetValue("deleted", false)
+	doc.SetValue("deleted_by", nil)
+}
+
+// documentDeleted returns true when the loaded row of the document is soft deleted.
+func documentDeleted(doc *Document) bool {
+	if !documentTime(doc, "deleted_at").IsZero() {
+		return true
+	}
+
+	deleted, _ := doc.Value("deleted")
+	return deleted == true
+}
+
+func isSoftDeletable(data documentData) bool {
+	return data.flag.Is(HasDeletedAt) || data.flag.Is(HasDeleted)
+}
+
+// deletedAtPrecision is the tolerance used to match records deleted together,
+// since database column may truncate or round the time to seconds.
+const deletedAtPrecision = time.Second
+
+// filterDeletedAt limits filter to records deleted at the given time, so only records deleted together are restored.
+func filterDeletedAt(filter FilterQuery, data documentData, deletedAt time.Time) FilterQuery {
+	if deletedAt.IsZero() || !data.flag.Is(HasDeletedAt) {
+		return filter
+	}
+
+	return filter.AndGt("deleted_at", deletedAt.Add(-deletedAtPrecision)).AndLt("deleted_at", deletedAt.Add(deletedAtPrecision))
+}
+
+// deletedTogether returns true when both times are within deleted at precision.
+func deletedTogether(a time.Time, b time.Time) bool {
+	d := a.Sub(b)
+	return d > -deletedAtPrecision && d < deletedAtPrecision
+}
+
+func (r repository) RestoreAny(ctx context.Context, records interface{}, queriers ...Querier) (int, error) {
+	finish := r.instrumenter.Observe(ctx, "rel-restore-any", "restoring multiple records")
+	defer finish(nil)
+
+	var (
+		cw    = fetchContext(ctx, r.rootAdapter)
+		col   = NewCollection(records)
+		query = Build(col.Table(), queriers...).Unscoped()
+	)
+
+	return r.restoreAny(cw, col.data, query)
+}
+
+func (r repository) MustRestoreAny(ctx context.Context, records interface{}, queriers ...Querier) int {
+	restoredCount, err := r.RestoreAny(ctx, records, queriers...)
+	must(err)
+	return restoredCount
+}
+
+// restoreAny reverts soft delete done by deleteAny.
+func (r repository) restoreAny(cw contextWrapper, data documentData, query Query) (int, error) {
+	flag := data.flag
+	hasDeletedAt := flag.Is(HasDeletedAt)
+	hasDeleted := flag.Is(HasDeleted)
+	if !isSoftDeletable(data) {
+		return 0, ErrNotSoftDeletable
+	}
+
+	mutates := make(map[string]Mutate, 1)
+	if hasDeletedAt {
+		mutates["deleted_at"] = Set("deleted_at", nil)
+	}
+	if hasDeleted {
+		mutates["deleted"] = Set("deleted", false)
+		if flag.Is(HasUpdatedAt) && !hasDeletedAt {
+			mutates[data.updatedAt] = Set(data.updatedAt, r.Now())
+		}
+	}
+	if flag.Is(HasVersioning) {
+		mutates["lock_version"] = Inc("lock_version")
+	}
+	if data.hasField("deleted_by") {
+		mutates["deleted_by"] = Set("deleted_by", nil)
+	}
+
+	return cw.adapter.Update(cw.ctx, query, "", mutates)
+}
+
+// saveVersion copies current row of the document to versions table.
+// The copied row is valid from its last update (or creation) until now, a soft deleted row is valid from its deletion.
+func (r repository) saveVersion(cw contextWrapper, doc *Document, filter FilterQuery) error {
+	var (
+		prev      = NewDocument(reflect.New(doc.rt))
+		validFrom time.Time
+	)
+
+	if err := r.find(cw, prev, Build(doc.Table(), filter, Unscoped(true))); err != nil {
+		return err
+	}
+
+	if prev.Flag(HasUpdatedAt) {
+		validFrom = documentTime(prev, prev.data.updatedAt)
+	}
+
+	if validFrom.IsZero() && prev.Flag(HasCreatedAt) {
+		validFrom = documentTime(prev, prev.data.createdAt)
+	}
+
+	if deletedAt := documentTime(prev, "deleted_at"); !deletedAt.IsZero() {
+		validFrom = deletedAt
+	}
+
+	mutates := make(map[string]Mutate, len(prev.Fields())+2)
+	for _, field := range prev.Fields() {
+		value, _ := prev.Value(field)
+		mutates[field] = Set(field, value)
+	}
+
+	mutates["valid_from"] = Set("valid_from", validFrom)
+	mutates["valid_to"] = Set("valid_to", r.Now())
+
+	_, err := cw.adapter.Insert(cw.ctx, Build(versionTable(doc.Table())), "", mutates, OnConflict{})
+	return err
+}
+
+func (r repository) Versions(ctx context.Context, record interface{}) ([]Version, error) {
+	finish := r.instrumenter.Observe(ctx, "rel-versions", "finding record versions")
+	defer finish(nil)
+
+	var (
+		cw    = fetchContext(ctx, r.rootAdapter)
+		doc   = NewDocument(record)
+		query = Build(versionTable(doc.Table()), filterDocument(doc), SortAsc("valid_to"))
+	)
+
+	cur, err := cw.adapter.Query(cw.ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanVersions(cur, doc.rt)
+}
+
+func (r repository) MustVersions(ctx context.Context, record interface{}) []Version {
+	versions, err := r.Versions(ctx, record)
+	must(err)
+	return versions
+}
+
+func (r repository) AsOf(ctx context.Context, record interface{}, t time.Time) error {
+	finish := r.instrumenter.Observe(ctx, "rel-as-of", "finding a record version")
+	defer finish(nil)
+
+	var (
+		cw     = fetchContext(ctx, r.rootAdapter)
+		doc    = NewDocument(record)
+		filter = filterDocument(doc)
+		query  = Build(versionTable(doc.Table()), filter, Lte("valid_from", t), Gt("valid_to", t), SortAsc("valid_to"), Unscoped(true))
+	)
+
+	if err := r.find(cw, doc, query); err == nil {
+		if documentDeleted(doc) {
+			return NotFoundError{}
+		}
+
+		return nil
+	} else if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	if err := r.find(cw, doc, Build(doc.Table(), filter)); err != nil {
+		return err
+	}
+
+	if doc.Flag(HasCreatedAt) && documentTime(doc, doc.data.createdAt).After(t) {
+		return NotFoundError{}
+	}
+
+	return nil
+}
+
+func (r repository) MustAsOf(ctx context.Context, record interface{}, t time.Time) {
+	must(r.AsOf(ctx, record, t))
+}
+
 func (r repository) Preload(ctx context.Context, records interface{}, field string, queriers ...Querier) error {
 	finish := r.instrumenter.Observe(ctx, "rel-preload", "preloading associations")
 	defer finish(nil)
@@ -1198,32 +1651,81 @@ func (r repository) MustExec(ctx context.Context, stmt string, args ...interface
 	return lastInsertedId, rowsAffected
 }
 
-func (r repository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+func (r repository) Transaction(ctx context.Context, fn func(ctx context.Context) error, options ...TransactionOption) error {
 	finish := r.instrumenter.Observe(ctx, "rel-transaction", "transaction")
 	defer finish(nil)
 
 	var (
-		cw = fetchContext(ctx, r.rootAdapter)
+		cw        = fetchContext(ctx, r.rootAdapter)
+		config    = applyTransactionOptions(options)
+		_, nested = ctx.Value(ctxKey).(Adapter)
 	)
 
-	return r.transaction(cw, func(cw contextWrapper) error {
-		return fn(cw.ctx)
-	})
+	// isolation level and access mode can't be changed in the middle of a transaction.
+	if nested {
+		outer, _ := ctx.Value(optionsCtxKey).(TransactionOptions)
+		if config.options != (TransactionOptions{}) && config.options != outer {
+			return ErrNestedTransactionOptions
+		}
+
+		config.options = TransactionOptions{}
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := r.transactionWithOptions(cw, config.options, func(cw contextWrapper) error {
+			return fn(cw.ctx)
+		})
+
+		if err == nil || nested || attempt >= config.retry.max || !isRetryable(err) {
+			return err
+		}
+
+		if err := config.retry.wait(ctx, attempt); err != nil {
+			return err
+		}
+	}
 }
 
 func (r repository) transaction(cw contextWrapper, fn func(cw contextWrapper) error) error {
-	adp, err := cw.adapter.Begin(cw.ctx)
+	return r.transactionWithOptions(cw, TransactionOptions{}, fn)
+}
+
+func (r repository) transactionWithOptions(cw contextWrapper, options TransactionOptions, fn func(cw contextWrapper) error) error {
+	adp, err := begin(cw, options)
 	if err != nil {
 		return err
 	}
 
+	var (
+		ctx               = cw.ctx
+		parent, nested    = fetchTransactionHooks(ctx)
+		hooks             = &transactionHooks{}
+		finishTransaction = func(committed bool) {
+			// nested transaction keeps its own callbacks, so callbacks of a rolled back nested transaction
+			// are handled like a rolled back savepoint even when the outer transaction commits.
+			switch {
+			case nested && committed:
+				parent.release(hooks)
+			case nested:
+				parent.rollbackTo(hooks)
+			default:
+				hooks.run(ctx, committed)
+			}
+		}
+	)
+
 	// wrap trx adapter to new context.
-	cw = wrapContext(cw.ctx, adp)
+	cw = wrapContext(ctx, adp)
+	cw.ctx = context.WithValue(cw.ctx, hooksCtxKey, hooks)
+	if !nested {
+		cw.ctx = context.WithValue(cw.ctx, optionsCtxKey, options)
+	}
 
 	func() {
 		defer func() {
 			if p := recover(); p != nil {
 				_ = cw.adapter.Rollback(cw.ctx)
+				finishTransaction(false)
 
 				switch e := p.(type) {
 				case runtime.Error:
@@ -1235,8 +1737,10 @@ func (r repository) transaction(cw contextWrapper, fn func(cw contextWrapper) er
 				}
 			} else if err != nil {
 				_ = cw.adapter.Rollback(cw.ctx)
+				finishTransaction(false)
 			} else {
 				err = cw.adapter.Commit(cw.ctx)
+				finishTransaction(err == nil)
 			}
 		}()
 
@@ -1246,8 +1750,93 @@ func (r repository) transaction(cw contextWrapper, fn func(cw contextWrapper) er
 	return err
 }
 
+func (r repository) Savepoint(ctx context.Context, name string, fn func(ctx context.Context) error) (err error) {
+	finish := r.instrumenter.Observe(ctx, "rel-savepoint", name)
+	defer finish(nil)
+
+	if _, ok := ctx.Value(ctxKey).(Adapter); !ok {
+		return ErrSavepointOutsideTransaction
+	}
+
+	savepointer, ok := r.Adapter(ctx).(Savepointer)
+	if !ok {
+		return ErrUnsupportedSavepoint
+	}
+
+	if err := savepointer.Savepoint(ctx, name); err != nil {
+		return err
+	}
+
+	var (
+		parent, _ = fetchTransactionHooks(ctx)
+		hooks     = &transactionHooks{}
+	)
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = savepointer.RollbackTo(ctx, name)
+			parent.rollbackTo(hooks)
+			panic(p)
+		} else if err != nil {
+			_ = savepointer.RollbackTo(ctx, name)
+			parent.rollbackTo(hooks)
+		} else if err = savepointer.Release(ctx, name); err != nil {
+			parent.rollbackTo(hooks)
+		} else {
+			parent.release(hooks)
+		}
+	}()
+
+	return fn(context.WithValue(ctx, hooksCtxKey, hooks))
+}
+
+// begin transaction using options aware Begin when options is specified,
+// so adapter that implements only Begin keeps working without options.
+func begin(cw contextWrapper, options TransactionOptions) (Adapter, error) {
+	if options == (TransactionOptions{}) {
+		return cw.adapter.Begin(cw.ctx)
+	}
+
+	beginner, ok := cw.adapter.(TransactionBeginner)
+	if !ok {
+		return nil, ErrUnsupportedTransactionOptions
+	}
+
+	return beginner.BeginTransaction(cw.ctx, options)
+}
+
+// RepositoryOption configures repository created using New.
+type RepositoryOption interface {
+	applyRepository(r *repository)
+}
+
+func (fn NowFunc) applyRepository(r *repository) {
+	r.clock = fn
+}
+
+// WithClock sets function used by repository to get current time for timestamps and soft delete,
+// instead of the package level Now.
+func WithClock(clock NowFunc) RepositoryOption {
+	return clock
+}
+
+// Clock is implemented by repository that keeps its own clock, such as repository created using New with WithClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// CurrentTime returns current time using clock of repo when it implements Clock, otherwise Now is used.
+// Packages that wrap a repository use it, so records they write share the clock of the repository.
+func CurrentTime(repo Repository) time.Time {
+	if clock, ok := repo.(Clock); ok {
+		return clock.Now()
+	}
+
+	return Now()
+}
+
 // New create new repo using adapter.
-func New(adapter Adapter) Repository {
+func New(adapter Adapter, options ...RepositoryOption) Repository {
 	repo := &repository{
 		rootAdapter:  adapter,
 		instrumenter: DefaultLogger,
@@ -1255,5 +1844,9 @@ func New(adapter Adapter) Repository {
 
 	repo.Instrumentation(DefaultLogger)
 
+	for i := range options {
+		options[i].applyRepository(repo)
+	}
+
 	return repo
 }
@@ -45,6 +45,34 @@ func TestNew(t *testing.T) {
 	assert.Equal(t, adapter, repo.Adapter(ctx))
 }
 
+func TestNew_withClock(t *testing.T) {
+	var (
+		ctx     = context.TODO()
+		now     = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		adapter = &testAdapter{}
+		repo    = New(adapter, WithClock(func() time.Time { return now }))
+		user    = User{Name: "Luffy"}
+		address = Address{ID: 1}
+	)
+
+	adapter.On("Insert", From("users"), map[string]Mutate{
+		"name":       Set("name", "Luffy"),
+		"age":        Set("age", 0),
+		"created_at": Set("created_at", now),
+		"updated_at": Set("updated_at", now),
+	}, OnConflict{}).Return(1, nil).Once()
+	adapter.On("Update", From("user_addresses").Where(Eq("id", 1)), "", map[string]Mutate{
+		"deleted_at": Set("deleted_at", now),
+	}).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Insert(ctx, &user))
+	assert.Equal(t, now, user.CreatedAt)
+	assert.Nil(t, repo.Delete(ctx, &address))
+	assert.Equal(t, now, *address.DeletedAt)
+
+	adapter.AssertExpectations(t)
+}
+
 func TestRepository_Instrumentation(t *testing.T) {
 	var (
 		repo = repository{rootAdapter: &testAdapter{}}
@@ -1527,6 +1555,64 @@ func TestRepository_Update_reloadError(t *testing.T) {
 	cur.AssertExpectations(t)
 }
 
+func TestRepository_Update_versioned(t *testing.T) {
+	var (
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		article   = Article{ID: 1, Title: "old"}
+		createdAt = Now().Add(-2 * time.Hour)
+		updatedAt = Now().Add(-time.Hour)
+		cur       = &testCursor{}
+		version   = map[string]Mutate{
+			"id":         Set("id", 1),
+			"title":      Set("title", "old"),
+			"created_at": Set("created_at", createdAt),
+			"updated_at": Set("updated_at", updatedAt),
+			"deleted_at": Set("deleted_at", nil),
+			"valid_from": Set("valid_from", updatedAt),
+			"valid_to":   Set("valid_to", Now()),
+		}
+		mutates = map[string]Mutate{
+			"title": Set("title", "new"),
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", From("articles").Where(Eq("id", 1)).Unscoped().Limit(1)).Return(cur, nil).Once()
+	adapter.On("Insert", From("articles_versions"), version, OnConflict{}).Return(nil, nil).Once()
+	adapter.On("Update", From("articles").Where(Eq("id", 1)).Where(Nil("deleted_at")), "id", mutates).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "title", "created_at", "updated_at", "deleted_at"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, "old", createdAt, updatedAt, nil).Once()
+
+	assert.Nil(t, repo.Update(context.TODO(), &article, Set("title", "new")))
+	assert.Equal(t, "new", article.Title)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Update_versionedNotFound(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		article = Article{ID: 1}
+		cur     = createCursor(0)
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", From("articles").Where(Eq("id", 1)).Unscoped().Limit(1)).Return(cur, nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, NotFoundError{}, repo.Update(context.TODO(), &article, Set("title", "new")))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
 func TestRepository_Update_saveBelongsTo(t *testing.T) {
 	var (
 		userID  = 1
@@ -2702,6 +2788,164 @@ func TestRepository_Delete_softDelete(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
+func TestRepository_Delete_versioned(t *testing.T) {
+	var (
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		article   = Article{ID: 1}
+		createdAt = Now().Add(-time.Hour)
+		cur       = &testCursor{}
+		version   = map[string]Mutate{
+			"id":         Set("id", 1),
+			"title":      Set("title", "title"),
+			"created_at": Set("created_at", createdAt),
+			"updated_at": Set("updated_at", time.Time{}),
+			"deleted_at": Set("deleted_at", nil),
+			"valid_from": Set("valid_from", createdAt),
+			"valid_to":   Set("valid_to", Now()),
+		}
+		mutates = map[string]Mutate{
+			"deleted_at": Set("deleted_at", Now()),
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", From("articles").Where(Eq("id", 1)).Unscoped().Limit(1)).Return(cur, nil).Once()
+	adapter.On("Insert", From("articles_versions"), version, OnConflict{}).Return(nil, nil).Once()
+	adapter.On("Update", From("articles").Where(Eq("id", 1)), "", mutates).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "title", "created_at"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, "title", createdAt).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &article))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Delete_versionedInsertError(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		article = Article{ID: 1}
+		cur     = createCursor(1)
+		err     = errors.New("error")
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", From("articles").Where(Eq("id", 1)).Unscoped().Limit(1)).Return(cur, nil).Once()
+	adapter.On("Insert", From("articles_versions"), mock.Anything, OnConflict{}).Return(nil, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Delete(context.TODO(), &article))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_forceDelete(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		address = Address{ID: 1}
+	)
+
+	adapter.On("Delete", From("user_addresses").Where(Eq("id", address.ID))).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &address, ForceDelete(true)))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_deletedBy(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		comment = Comment{ID: 1}
+		ctx     = WithDeletedBy(context.TODO(), "admin")
+		mutates = map[string]Mutate{
+			"deleted":    Set("deleted", true),
+			"deleted_by": Set("deleted_by", "admin"),
+			"updated_at": Set("updated_at", Now()),
+		}
+	)
+
+	adapter.On("Update", From("comments").Where(Eq("id", comment.ID)), "", mutates).Return(1, nil).Once()
+
+	assert.Nil(t, repo.Delete(ctx, &comment))
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_cascadeSoftDelete(t *testing.T) {
+	var (
+		deletedAt = Now()
+		tick      = deletedAt
+		// every call to clock returns different time, children must use the same time as parent.
+		clock = func() time.Time {
+			t := tick
+			tick = tick.Add(time.Second)
+			return t
+		}
+		adapter = &testAdapter{}
+		repo    = New(adapter, WithClock(clock))
+		post    = Post{
+			ID:          1,
+			Summary:     &PostSummary{ID: 2, PostID: 1},
+			Comments:    []Comment{{ID: 3, PostID: 1}},
+			Attachments: []Attachment{{ID: 4, PostID: 1}},
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("post_summaries").Where(Eq("id", 2).AndEq("post_id", 1)), "", map[string]Mutate{
+		"deleted_at": Set("deleted_at", deletedAt),
+	}).Return(1, nil).Once()
+	adapter.On("Update", From("comments").Where(Eq("post_id", 1).AndIn("id", 3)), "", map[string]Mutate{
+		"deleted":    Set("deleted", true),
+		"updated_at": Set("updated_at", deletedAt),
+	}).Return(1, nil).Once()
+	adapter.On("Delete", From("attachments").Where(Eq("post_id", 1).AndIn("id", 4))).Return(1, nil).Once()
+	adapter.On("Update", From("posts").Where(Eq("id", 1)), "", map[string]Mutate{
+		"deleted_at": Set("deleted_at", deletedAt),
+	}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &post, Cascade(true)))
+	assert.Equal(t, deletedAt, *post.DeletedAt)
+	assert.Equal(t, deletedAt, *post.Summary.DeletedAt)
+	assert.True(t, post.Comments[0].Deleted)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Delete_cascadeForceDelete(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		post    = Post{
+			ID:       1,
+			Summary:  &PostSummary{ID: 2, PostID: 1},
+			Comments: []Comment{{ID: 3, PostID: 1}},
+		}
+	)
+
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Delete", From("post_summaries").Where(Eq("id", 2).AndEq("post_id", 1))).Return(1, nil).Once()
+	adapter.On("Delete", From("comments").Where(Eq("post_id", 1).AndIn("id", 3))).Return(1, nil).Once()
+	adapter.On("Delete", From("posts").Where(Eq("id", 1))).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	assert.Nil(t, repo.Delete(context.TODO(), &post, Cascade(true), ForceDelete(true)))
+	assert.Nil(t, post.DeletedAt)
+	assert.Nil(t, post.Summary.DeletedAt)
+	assert.False(t, post.Comments[0].Deleted)
+
+	adapter.AssertExpectations(t)
+}
+
 func TestRepository_Delete_softAltDelete(t *testing.T) {
 	var (
 		adapter    = &testAdapter{}
@@ -3104,949 +3348,1882 @@ func TestRepository_MustDeleteAny(t *testing.T) {
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Preload_hasOne(t *testing.T) {
+func TestRepository_Versions(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		user    = User{ID: 10}
-		address = Address{ID: 100, UserID: &user.ID}
+		article = Article{ID: 1}
+		created = Now().Add(-3 * time.Hour)
+		first   = Now().Add(-2 * time.Hour)
+		second  = Now().Add(-time.Hour)
 		cur     = &testCursor{}
 	)
 
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
+	adapter.On("Query", From("articles_versions").Where(Eq("id", 1)).SortAsc("valid_to")).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Once()
-	cur.MockScan(address.ID, *address.UserID).Times(2)
+	cur.On("Fields").Return([]string{"id", "title", "valid_from", "valid_to"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(1, "first", created, first).Once()
+	cur.MockScan(1, "second", first, second).Once()
 	cur.On("Next").Return(false).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &user, "address"))
-	assert.Equal(t, address, user.Address)
+	assert.Equal(t, []Version{
+		{ValidFrom: created, ValidTo: first, Record: &Article{ID: 1, Title: "first"}},
+		{ValidFrom: first, ValidTo: second, Record: &Article{ID: 1, Title: "second"}},
+	}, repo.MustVersions(context.TODO(), &article))
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrHasOne(t *testing.T) {
+func TestRepository_Versions_error(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		user    = User{ID: 10}
-		address = Address{ID: 100, UserID: &user.ID}
+		article = Article{ID: 1}
+		err     = errors.New("error")
+	)
+
+	adapter.On("Query", From("articles_versions").Where(Eq("id", 1)).SortAsc("valid_to")).Return(&testCursor{}, err).Once()
+
+	versions, verr := repo.Versions(context.TODO(), &article)
+	assert.Nil(t, versions)
+	assert.Equal(t, err, verr)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_AsOf(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		article = Article{ID: 1}
+		at      = Now().Add(-time.Hour)
 		cur     = &testCursor{}
 	)
 
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
+	adapter.On("Query", From("articles_versions").Where(Eq("id", 1), Lte("valid_from", at), Gt("valid_to", at)).SortAsc("valid_to").Unscoped().Limit(1)).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Fields").Return([]string{"id", "title", "valid_from", "valid_to"}, nil).Once()
 	cur.On("Next").Return(true).Once()
-	cur.MockScan(address.ID, *address.UserID).Times(2)
-	cur.On("Next").Return(false).Once()
+	cur.MockScan(1, "old", at.Add(-time.Hour), Now()).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &user, "work_address"))
-	assert.Equal(t, &address, user.WorkAddress)
+	assert.NotPanics(t, func() {
+		repo.MustAsOf(context.TODO(), &article, at)
+	})
+	assert.Equal(t, Article{ID: 1, Title: "old"}, article)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrHasOne_notFound_nil(t *testing.T) {
+func TestRepository_AsOf_current(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-		user    = User{ID: 10}
-		cur     = &testCursor{}
+		adapter    = &testAdapter{}
+		repo       = New(adapter)
+		article    = Article{ID: 1}
+		at         = Now().Add(-time.Hour)
+		versionCur = createCursor(0)
+		cur        = &testCursor{}
 	)
 
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
+	adapter.On("Query", From("articles_versions").Where(Eq("id", 1), Lte("valid_from", at), Gt("valid_to", at)).SortAsc("valid_to").Unscoped().Limit(1)).Return(versionCur, nil).Once()
+	adapter.On("Query", From("articles").Where(Eq("id", 1)).Where(Nil("deleted_at")).Limit(1)).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(false).Once()
+	cur.On("Fields").Return([]string{"id", "title", "created_at"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, "current", at.Add(-time.Hour)).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &user, "work_address"))
-	assert.Nil(t, user.WorkAddress)
+	assert.Nil(t, repo.AsOf(context.TODO(), &article, at))
+	assert.Equal(t, Article{ID: 1, Title: "current", CreatedAt: at.Add(-time.Hour)}, article)
 
 	adapter.AssertExpectations(t)
+	versionCur.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_sliceHasOne(t *testing.T) {
+func TestRepository_AsOf_notCreated(t *testing.T) {
 	var (
-		adapter   = &testAdapter{}
-		repo      = New(adapter)
-		users     = []User{{ID: 10}, {ID: 20}}
-		addresses = []Address{
-			{ID: 100, UserID: &users[0].ID},
-			{ID: 200, UserID: &users[1].ID},
-		}
-		cur = &testCursor{}
+		adapter    = &testAdapter{}
+		repo       = New(adapter)
+		article    = Article{ID: 1}
+		at         = Now().Add(-time.Hour)
+		versionCur = createCursor(0)
+		cur        = &testCursor{}
 	)
 
-	// one of these, because of map ordering
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("articles_versions").Where(Eq("id", 1), Lte("valid_from", at), Gt("valid_to", at)).SortAsc("valid_to").Unscoped().Limit(1)).Return(versionCur, nil).Once()
+	adapter.On("Query", From("articles").Where(Eq("id", 1)).Where(Nil("deleted_at")).Limit(1)).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
-	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
-	cur.On("Next").Return(false).Once()
+	cur.On("Fields").Return([]string{"id", "title", "created_at"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, "current", Now()).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &users, "address"))
-	assert.Equal(t, addresses[0], users[0].Address)
-	assert.Equal(t, addresses[1], users[1].Address)
+	assert.Equal(t, NotFoundError{}, repo.AsOf(context.TODO(), &article, at))
 
 	adapter.AssertExpectations(t)
+	versionCur.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrSliceHasOne(t *testing.T) {
+func TestRepository_AsOf_deleted(t *testing.T) {
 	var (
 		adapter   = &testAdapter{}
 		repo      = New(adapter)
-		users     = []User{{ID: 10}, {ID: 20}}
-		addresses = []Address{
-			{ID: 100, UserID: &users[0].ID},
-			{ID: 200, UserID: &users[1].ID},
-		}
-		cur = &testCursor{}
+		article   = Article{ID: 1}
+		at        = Now().Add(-time.Hour)
+		deletedAt = at.Add(-time.Minute)
+		cur       = &testCursor{}
 	)
 
-	// one of these, because of map ordering
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("articles_versions").Where(Eq("id", 1), Lte("valid_from", at), Gt("valid_to", at)).SortAsc("valid_to").Unscoped().Limit(1)).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
-	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
-	cur.On("Next").Return(false).Once()
+	cur.On("Fields").Return([]string{"id", "title", "deleted_at", "valid_from", "valid_to"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, "deleted", &deletedAt, deletedAt, Now()).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &users, "work_address"))
-	assert.Equal(t, &addresses[0], users[0].WorkAddress)
-	assert.Equal(t, &addresses[1], users[1].WorkAddress)
+	assert.Equal(t, NotFoundError{}, repo.AsOf(context.TODO(), &article, at))
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrSliceHasOne_notFound_null(t *testing.T) {
+func TestRepository_AsOf_error(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		users   = []User{{ID: 10}, {ID: 20}}
-		cur     = &testCursor{}
+		article = Article{ID: 1}
+		at      = Now().Add(-time.Hour)
+		err     = errors.New("error")
 	)
 
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Query", From("articles_versions").Where(Eq("id", 1), Lte("valid_from", at), Gt("valid_to", at)).SortAsc("valid_to").Unscoped().Limit(1)).Return(&testCursor{}, err).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &users, "work_address"))
-	assert.Nil(t, users[0].WorkAddress)
-	assert.Nil(t, users[1].WorkAddress)
+	assert.Equal(t, err, repo.AsOf(context.TODO(), &article, at))
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nestedHasOne(t *testing.T) {
+func TestRepository_Restore(t *testing.T) {
 	var (
-		adapter     = &testAdapter{}
-		repo        = New(adapter)
-		transaction = Transaction{
-			Buyer: User{ID: 10},
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		deletedAt = Now()
+		address   = Address{ID: 1, DeletedAt: &deletedAt}
+		mutates   = map[string]Mutate{
+			"deleted_at": Set("deleted_at", nil),
 		}
-		address = Address{ID: 100, UserID: &transaction.Buyer.ID}
-		cur     = &testCursor{}
 	)
 
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Once()
-	cur.MockScan(address.ID, *address.UserID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Update", From("user_addresses").Where(Eq("id", address.ID)).Unscoped(), "", mutates).Return(1, nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer.address"))
-	assert.Equal(t, address, transaction.Buyer.Address)
+	assert.NotPanics(t, func() {
+		repo.MustRestore(context.TODO(), &address)
+	})
+	assert.Nil(t, address.DeletedAt)
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrNestedHasOne(t *testing.T) {
+func TestRepository_Restore_softAltDelete(t *testing.T) {
 	var (
-		adapter     = &testAdapter{}
-		repo        = New(adapter)
-		transaction = Transaction{
-			Buyer: User{ID: 10},
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		comment = Comment{ID: 1, Deleted: true, DeletedBy: "admin"}
+		mutates = map[string]Mutate{
+			"deleted":    Set("deleted", false),
+			"deleted_by": Set("deleted_by", nil),
+			"updated_at": Set("updated_at", Now()),
 		}
-		address = Address{ID: 100, UserID: &transaction.Buyer.ID}
-		cur     = &testCursor{}
 	)
 
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Once()
-	cur.MockScan(address.ID, *address.UserID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Update", From("comments").Where(Eq("id", comment.ID)).Unscoped(), "", mutates).Return(1, nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer.work_address"))
-	assert.Equal(t, &address, transaction.Buyer.WorkAddress)
+	assert.Nil(t, repo.Restore(context.TODO(), &comment))
+	assert.Equal(t, Comment{ID: 1}, comment)
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrNestedHasOne_notFound_null(t *testing.T) {
+func TestRepository_Restore_versioned(t *testing.T) {
 	var (
-		adapter     = &testAdapter{}
-		repo        = New(adapter)
-		transaction = Transaction{
-			Buyer: User{ID: 10},
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		createdAt = Now().Add(-2 * time.Hour)
+		deletedAt = Now().Add(-time.Hour)
+		article   = Article{ID: 1, DeletedAt: &deletedAt}
+		cur       = &testCursor{}
+		version   = map[string]Mutate{
+			"id":         Set("id", 1),
+			"title":      Set("title", "title"),
+			"created_at": Set("created_at", createdAt),
+			"updated_at": Set("updated_at", time.Time{}),
+			"deleted_at": Set("deleted_at", deletedAt),
+			"valid_from": Set("valid_from", deletedAt),
+			"valid_to":   Set("valid_to", Now()),
+		}
+		mutates = map[string]Mutate{
+			"deleted_at": Set("deleted_at", nil),
 		}
-		cur = &testCursor{}
 	)
 
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", From("articles").Where(Eq("id", 1)).Unscoped().Limit(1)).Return(cur, nil).Once()
+	adapter.On("Insert", From("articles_versions"), version, OnConflict{}).Return(nil, nil).Once()
+	adapter.On("Update", From("articles").Where(Eq("id", 1)).Unscoped(), "", mutates).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(false).Once()
+	cur.On("Fields").Return([]string{"id", "title", "created_at", "deleted_at"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(1, "title", createdAt, &deletedAt).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer.work_address"))
-	assert.Nil(t, transaction.Buyer.WorkAddress)
+	assert.Nil(t, repo.Restore(context.TODO(), &article))
+	assert.Nil(t, article.DeletedAt)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_sliceNestedHasOne(t *testing.T) {
+func TestRepository_Restore_versionedInsertError(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = New(adapter)
-		transactions = []Transaction{
-			{Buyer: User{ID: 10}},
-			{Buyer: User{ID: 20}},
-		}
-		addresses = []Address{
-			{ID: 100, UserID: &transactions[0].Buyer.ID},
-			{ID: 200, UserID: &transactions[1].Buyer.ID},
-		}
-		cur = &testCursor{}
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		deletedAt = Now()
+		article   = Article{ID: 1, DeletedAt: &deletedAt}
+		cur       = createCursor(1)
+		err       = errors.New("error")
 	)
 
-	// one of these, because of map ordering
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
-	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Query", From("articles").Where(Eq("id", 1)).Unscoped().Limit(1)).Return(cur, nil).Once()
+	adapter.On("Insert", From("articles_versions"), mock.Anything, OnConflict{}).Return(nil, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer.address"))
-	assert.Equal(t, addresses[0], transactions[0].Buyer.Address)
-	assert.Equal(t, addresses[1], transactions[1].Buyer.Address)
+	assert.Equal(t, err, repo.Restore(context.TODO(), &article))
+	assert.Equal(t, &deletedAt, article.DeletedAt)
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrSliceNestedHasOne(t *testing.T) {
+func TestRepository_Restore_notFound(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = New(adapter)
-		transactions = []Transaction{
-			{Buyer: User{ID: 10}},
-			{Buyer: User{ID: 20}},
-		}
-		addresses = []Address{
-			{ID: 100, UserID: &transactions[0].Buyer.ID},
-			{ID: 200, UserID: &transactions[1].Buyer.ID},
-		}
-		cur = &testCursor{}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		address = Address{ID: 1}
 	)
 
-	// one of these, because of map ordering
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
-	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Update", From("user_addresses").Where(Eq("id", address.ID)).Unscoped(), "", mock.Anything).Return(0, nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer.work_address"))
-	assert.Equal(t, &addresses[0], transactions[0].Buyer.WorkAddress)
-	assert.Equal(t, &addresses[1], transactions[1].Buyer.WorkAddress)
+	assert.Equal(t, NotFoundError{}, repo.Restore(context.TODO(), &address))
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrSliceNestedHasOne_notFound_null(t *testing.T) {
+func TestRepository_Restore_notSoftDeletable(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = New(adapter)
-		transactions = []Transaction{
-			{Buyer: User{ID: 10}},
-			{Buyer: User{ID: 20}},
-		}
-		cur = &testCursor{}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		user    = User{ID: 1}
 	)
 
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(false).Once()
-
-	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer.work_address"))
-	assert.Nil(t, transactions[0].Buyer.WorkAddress)
-	assert.Nil(t, transactions[1].Buyer.WorkAddress)
+	assert.Equal(t, ErrNotSoftDeletable, repo.Restore(context.TODO(), &user))
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_hasMany(t *testing.T) {
+func TestRepository_Restore_cascade(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = New(adapter)
-		user         = User{ID: 10}
-		transactions = []Transaction{
-			{ID: 5, BuyerID: 10},
-			{ID: 10, BuyerID: 10},
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		deletedAt = Now().Add(-time.Hour)
+		post      = Post{
+			ID:          1,
+			Summary:     &PostSummary{ID: 2, PostID: 1, DeletedAt: &deletedAt},
+			Comments:    []Comment{{ID: 3, PostID: 1, Deleted: true}},
+			Attachments: []Attachment{{ID: 4, PostID: 1}},
+			DeletedAt:   &deletedAt,
 		}
-		cur = &testCursor{}
 	)
 
-	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
-	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("posts").Where(Eq("id", 1)).Unscoped(), "", map[string]Mutate{
+		"deleted_at": Set("deleted_at", nil),
+	}).Return(1, nil).Once()
+	adapter.On("Update", From("post_summaries").Where(Eq("id", 2).AndEq("post_id", 1).AndGt("deleted_at", deletedAt.Add(-time.Second)).AndLt("deleted_at", deletedAt.Add(time.Second))).Unscoped(), "", map[string]Mutate{
+		"deleted_at": Set("deleted_at", nil),
+	}).Return(1, nil).Once()
+	adapter.On("Update", From("comments").Where(Eq("post_id", 1).AndIn("id", 3)).Unscoped(), "", map[string]Mutate{
+		"deleted":    Set("deleted", false),
+		"deleted_by": Set("deleted_by", nil),
+		"updated_at": Set("updated_at", Now()),
+	}).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &user, "transactions"))
-	assert.Equal(t, transactions, user.Transactions)
+	assert.Nil(t, repo.Restore(context.TODO(), &post, Cascade(true)))
+	assert.Nil(t, post.DeletedAt)
+	assert.Nil(t, post.Summary.DeletedAt)
+	assert.False(t, post.Comments[0].Deleted)
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_sliceHasMany(t *testing.T) {
+func TestRepository_Restore_cascadeNotDeletedTogether(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = New(adapter)
-		users        = []User{{ID: 10}, {ID: 20}}
-		transactions = []Transaction{
-			{ID: 5, BuyerID: 10},
-			{ID: 10, BuyerID: 10},
-			{ID: 15, BuyerID: 20},
-			{ID: 20, BuyerID: 20},
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		deletedAt = Now().Add(-time.Hour)
+		earlier   = deletedAt.Add(-time.Hour)
+		post      = Post{
+			ID:        1,
+			Summary:   &PostSummary{ID: 2, PostID: 1, DeletedAt: &earlier},
+			DeletedAt: &deletedAt,
 		}
-		cur = &testCursor{}
 	)
 
-	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10))).Return(cur, nil).Maybe()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Times(4)
-	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
-	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
-	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
-	cur.MockScan(transactions[3].ID, transactions[3].BuyerID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("posts").Where(Eq("id", 1)).Unscoped(), "", mock.Anything).Return(1, nil).Once()
+	adapter.On("Update", From("post_summaries").Where(Eq("id", 2).AndEq("post_id", 1).AndGt("deleted_at", deletedAt.Add(-time.Second)).AndLt("deleted_at", deletedAt.Add(time.Second))).Unscoped(), "", mock.Anything).Return(0, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &users, "transactions"))
-	assert.Equal(t, transactions[:2], users[0].Transactions)
-	assert.Equal(t, transactions[2:], users[1].Transactions)
+	assert.Nil(t, repo.Restore(context.TODO(), &post, Cascade(true)))
+	assert.Nil(t, post.DeletedAt)
+	assert.Equal(t, earlier, *post.Summary.DeletedAt)
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nestedHasMany(t *testing.T) {
+func TestRepository_Restore_cascadeTruncatedDeletedAt(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = New(adapter)
-		address      = Address{User: &User{ID: 10}}
-		transactions = []Transaction{
-			{ID: 5, BuyerID: 10},
-			{ID: 10, BuyerID: 10},
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		deletedAt = time.Date(2021, 1, 2, 3, 4, 5, 678000000, time.UTC)
+		truncated = deletedAt.Truncate(time.Second)
+		post      = Post{
+			ID:        1,
+			Summary:   &PostSummary{ID: 2, PostID: 1, DeletedAt: &truncated},
+			DeletedAt: &deletedAt,
 		}
-
-		cur = &testCursor{}
 	)
 
-	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
-	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("posts").Where(Eq("id", 1)).Unscoped(), "", mock.Anything).Return(1, nil).Once()
+	adapter.On("Update", From("post_summaries").Where(Eq("id", 2).AndEq("post_id", 1).AndGt("deleted_at", deletedAt.Add(-time.Second)).AndLt("deleted_at", deletedAt.Add(time.Second))).Unscoped(), "", mock.Anything).Return(1, nil).Once()
+	adapter.On("Commit").Return(nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user.transactions"))
-	assert.Equal(t, transactions, address.User.Transactions)
+	assert.Nil(t, repo.Restore(context.TODO(), &post, Cascade(true)))
+	assert.Nil(t, post.Summary.DeletedAt)
+	assert.True(t, deletedTogether(truncated, deletedAt))
+	assert.False(t, deletedTogether(deletedAt.Add(-time.Second), deletedAt))
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nestedNullHasMany(t *testing.T) {
+func TestRepository_Restore_cascadeError(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		address = Address{User: nil}
+		post    = Post{
+			ID:       1,
+			Comments: []Comment{{ID: 3, PostID: 1, Deleted: true}},
+		}
+		err = errors.New("error")
 	)
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user.transactions"))
-	assert.Nil(t, address.User)
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Update", From("posts").Where(Eq("id", 1)).Unscoped(), "", mock.Anything).Return(1, nil).Once()
+	adapter.On("Update", From("comments").Where(Eq("post_id", 1).AndIn("id", 3)).Unscoped(), "", mock.Anything).Return(0, err).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Equal(t, err, repo.Restore(context.TODO(), &post, Cascade(true)))
 
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nestedSliceHasMany(t *testing.T) {
+func TestRepository_RestoreAny(t *testing.T) {
 	var (
-		adapter   = &testAdapter{}
-		repo      = New(adapter)
-		addresses = []Address{
-			{User: &User{ID: 10}},
-			{User: &User{ID: 20}},
-		}
-		transactions = []Transaction{
-			{ID: 5, BuyerID: 10},
-			{ID: 10, BuyerID: 10},
-			{ID: 15, BuyerID: 20},
-			{ID: 20, BuyerID: 20},
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		mutates = map[string]Mutate{
+			"deleted_at": Set("deleted_at", nil),
 		}
-		cur = &testCursor{}
 	)
 
-	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10))).Return(cur, nil).Maybe()
-
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Times(4)
-	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
-	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
-	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
-	cur.MockScan(transactions[3].ID, transactions[3].BuyerID).Twice()
-	cur.On("Next").Return(false).Once()
+	adapter.On("Update", From("user_addresses").Where(Eq("user_id", 1)).Unscoped(), "", mutates).Return(2, nil).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
-	assert.Equal(t, transactions[:2], addresses[0].User.Transactions)
-	assert.Equal(t, transactions[2:], addresses[1].User.Transactions)
+	assert.Equal(t, 2, repo.MustRestoreAny(context.TODO(), &[]Address{}, Where(Eq("user_id", 1))))
 
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nestedNullSliceHasMany(t *testing.T) {
+func TestRepository_RestoreAny_notSoftDeletable(t *testing.T) {
 	var (
-		adapter   = &testAdapter{}
-		repo      = New(adapter)
-		addresses = []Address{
-			{User: &User{ID: 10}},
-			{User: nil},
-			{User: &User{ID: 15}},
-		}
-		transactions = []Transaction{
-			{ID: 5, BuyerID: 10},
-			{ID: 10, BuyerID: 10},
-			{ID: 15, BuyerID: 15},
-		}
-		cur = &testCursor{}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
 	)
 
-	adapter.On("Query", From("transactions").Where(In("user_id", 10, 15))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("transactions").Where(In("user_id", 15, 10))).Return(cur, nil).Maybe()
+	assert.Panics(t, func() {
+		repo.MustRestoreAny(context.TODO(), &[]User{})
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_hasOne(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		user    = User{ID: 10}
+		address = Address{ID: 100, UserID: &user.ID}
+		cur     = &testCursor{}
+	)
+
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
 	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
-	cur.On("Next").Return(true).Times(3)
-	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
-	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
-	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(address.ID, *address.UserID).Times(2)
 	cur.On("Next").Return(false).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
-	assert.Equal(t, transactions[:2], addresses[0].User.Transactions)
-	assert.Nil(t, addresses[1].User)
-	assert.Equal(t, transactions[2:], addresses[2].User.Transactions)
+	assert.Nil(t, repo.Preload(context.TODO(), &user, "address"))
+	assert.Equal(t, address, user.Address)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_belongsTo(t *testing.T) {
+func TestRepository_Preload_ptrHasOne(t *testing.T) {
 	var (
-		adapter     = &testAdapter{}
-		repo        = New(adapter)
-		user        = User{ID: 10, Name: "Del Piero"}
-		transaction = Transaction{BuyerID: 10}
-		cur         = &testCursor{}
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		user    = User{ID: 10}
+		address = Address{ID: 100, UserID: &user.ID}
+		cur     = &testCursor{}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
 	cur.On("Next").Return(true).Once()
-	cur.MockScan(user.ID, user.Name).Twice()
+	cur.MockScan(address.ID, *address.UserID).Times(2)
 	cur.On("Next").Return(false).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer"))
-	assert.Equal(t, user, transaction.Buyer)
+	assert.Nil(t, repo.Preload(context.TODO(), &user, "work_address"))
+	assert.Equal(t, &address, user.WorkAddress)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrBelongsTo(t *testing.T) {
+func TestRepository_Preload_ptrHasOne_notFound_nil(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		user    = User{ID: 10, Name: "Del Piero"}
-		address = Address{UserID: &user.ID}
+		user    = User{ID: 10}
 		cur     = &testCursor{}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
-	cur.On("Next").Return(true).Once()
-	cur.MockScan(user.ID, user.Name).Twice()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
 	cur.On("Next").Return(false).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
-	assert.Equal(t, user, *address.User)
+	assert.Nil(t, repo.Preload(context.TODO(), &user, "work_address"))
+	assert.Nil(t, user.WorkAddress)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_nullBelongsTo(t *testing.T) {
+func TestRepository_Preload_sliceHasOne(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-		address = Address{}
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		users     = []User{{ID: 10}, {ID: 20}}
+		addresses = []Address{
+			{ID: 100, UserID: &users[0].ID},
+			{ID: 200, UserID: &users[1].ID},
+		}
+		cur = &testCursor{}
 	)
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
-	assert.Nil(t, address.User)
+	// one of these, because of map ordering
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
+	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &users, "address"))
+	assert.Equal(t, addresses[0], users[0].Address)
+	assert.Equal(t, addresses[1], users[1].Address)
 
 	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrBelongsTo_notFound_null(t *testing.T) {
+func TestRepository_Preload_ptrSliceHasOne(t *testing.T) {
+	var (
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		users     = []User{{ID: 10}, {ID: 20}}
+		addresses = []Address{
+			{ID: 100, UserID: &users[0].ID},
+			{ID: 200, UserID: &users[1].ID},
+		}
+		cur = &testCursor{}
+	)
+
+	// one of these, because of map ordering
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
+	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &users, "work_address"))
+	assert.Equal(t, &addresses[0], users[0].WorkAddress)
+	assert.Equal(t, &addresses[1], users[1].WorkAddress)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_ptrSliceHasOne_notFound_null(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		userID  = 10
-		address = Address{UserID: &userID}
+		users   = []User{{ID: 10}, {ID: 20}}
 		cur     = &testCursor{}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
 	cur.On("Next").Return(false).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
-	assert.Nil(t, address.User)
+	assert.Nil(t, repo.Preload(context.TODO(), &users, "work_address"))
+	assert.Nil(t, users[0].WorkAddress)
+	assert.Nil(t, users[1].WorkAddress)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_sliceBelongsTo(t *testing.T) {
+func TestRepository_Preload_nestedHasOne(t *testing.T) {
 	var (
-		adapter      = &testAdapter{}
-		repo         = New(adapter)
-		transactions = []Transaction{
-			{BuyerID: 10},
-			{BuyerID: 20},
-		}
-		users = []User{
-			{ID: 10, Name: "Del Piero"},
-			{ID: 20, Name: "Nedved"},
+		adapter     = &testAdapter{}
+		repo        = New(adapter)
+		transaction = Transaction{
+			Buyer: User{ID: 10},
 		}
-		cur = &testCursor{}
+		address = Address{ID: 100, UserID: &transaction.Buyer.ID}
+		cur     = &testCursor{}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10, 20))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("users").Where(In("id", 20, 10))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(users[0].ID, users[0].Name).Twice()
-	cur.MockScan(users[1].ID, users[1].Name).Twice()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(address.ID, *address.UserID).Twice()
 	cur.On("Next").Return(false).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer"))
-	assert.Equal(t, users[0], transactions[0].Buyer)
-	assert.Equal(t, users[1], transactions[1].Buyer)
+	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer.address"))
+	assert.Equal(t, address, transaction.Buyer.Address)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrSliceBelongsTo(t *testing.T) {
+func TestRepository_Preload_ptrNestedHasOne(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-		users   = []User{
-			{ID: 10, Name: "Del Piero"},
-			{ID: 20, Name: "Nedved"},
-		}
-		addresses = []Address{
-			{UserID: &users[0].ID},
-			{UserID: &users[1].ID},
+		adapter     = &testAdapter{}
+		repo        = New(adapter)
+		transaction = Transaction{
+			Buyer: User{ID: 10},
 		}
-		cur = &testCursor{}
+		address = Address{ID: 100, UserID: &transaction.Buyer.ID}
+		cur     = &testCursor{}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10, 20))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("users").Where(In("id", 20, 10))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
-	cur.On("Next").Return(true).Twice()
-	cur.MockScan(users[0].ID, users[0].Name).Twice()
-	cur.MockScan(users[1].ID, users[1].Name).Twice()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(address.ID, *address.UserID).Twice()
 	cur.On("Next").Return(false).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user"))
-	assert.Equal(t, users[0], *addresses[0].User)
-	assert.Equal(t, users[1], *addresses[1].User)
+	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer.work_address"))
+	assert.Equal(t, &address, transaction.Buyer.WorkAddress)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_ptrSliceBelongsTo_notFound_null(t *testing.T) {
+func TestRepository_Preload_ptrNestedHasOne_notFound_null(t *testing.T) {
 	var (
-		adapter = &testAdapter{}
-		repo    = New(adapter)
-		users   = []User{
-			{ID: 10, Name: "Del Piero"},
-			{ID: 20, Name: "Nedved"},
-		}
-		addresses = []Address{
-			{UserID: &users[0].ID},
-			{UserID: &users[1].ID},
+		adapter     = &testAdapter{}
+		repo        = New(adapter)
+		transaction = Transaction{
+			Buyer: User{ID: 10},
 		}
 		cur = &testCursor{}
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10, 20))).Return(cur, nil).Maybe()
-	adapter.On("Query", From("users").Where(In("id", 20, 10))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10).AndNil("deleted_at"))).Return(cur, nil).Once()
 
 	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
 	cur.On("Next").Return(false).Once()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user"))
-	assert.Nil(t, addresses[0].User)
-	assert.Nil(t, addresses[1].User)
+	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer.work_address"))
+	assert.Nil(t, transaction.Buyer.WorkAddress)
 
 	adapter.AssertExpectations(t)
 	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_sliceNestedBelongsTo(t *testing.T) {
+func TestRepository_Preload_sliceNestedHasOne(t *testing.T) {
 	var (
 		adapter      = &testAdapter{}
 		repo         = New(adapter)
-		address      = Address{ID: 10, Street: "Continassa"}
 		transactions = []Transaction{
-			{AddressID: 10},
+			{Buyer: User{ID: 10}},
+			{Buyer: User{ID: 20}},
 		}
-		users = []User{
-			{ID: 10, Name: "Del Piero", Transactions: transactions},
-			{ID: 20, Name: "Nedved"},
+		addresses = []Address{
+			{ID: 100, UserID: &transactions[0].Buyer.ID},
+			{ID: 200, UserID: &transactions[1].Buyer.ID},
 		}
 		cur = &testCursor{}
 	)
 
-	adapter.On("Query", From("user_addresses").Where(In("id", 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	// one of these, because of map ordering
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
+	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer.address"))
+	assert.Equal(t, addresses[0], transactions[0].Buyer.Address)
+	assert.Equal(t, addresses[1], transactions[1].Buyer.Address)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_ptrSliceNestedHasOne(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = New(adapter)
+		transactions = []Transaction{
+			{Buyer: User{ID: 10}},
+			{Buyer: User{ID: 20}},
+		}
+		addresses = []Address{
+			{ID: 100, UserID: &transactions[0].Buyer.ID},
+			{ID: 200, UserID: &transactions[1].Buyer.ID},
+		}
+		cur = &testCursor{}
+	)
+
+	// one of these, because of map ordering
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(addresses[0].ID, *addresses[0].UserID).Twice()
+	cur.MockScan(addresses[1].ID, *addresses[1].UserID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer.work_address"))
+	assert.Equal(t, &addresses[0], transactions[0].Buyer.WorkAddress)
+	assert.Equal(t, &addresses[1], transactions[1].Buyer.WorkAddress)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_ptrSliceNestedHasOne_notFound_null(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = New(adapter)
+		transactions = []Transaction{
+			{Buyer: User{ID: 10}},
+			{Buyer: User{ID: 20}},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 10, 20).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("user_addresses").Where(In("user_id", 20, 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer.work_address"))
+	assert.Nil(t, transactions[0].Buyer.WorkAddress)
+	assert.Nil(t, transactions[1].Buyer.WorkAddress)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_hasMany(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = New(adapter)
+		user         = User{ID: 10}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &user, "transactions"))
+	assert.Equal(t, transactions, user.Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_sliceHasMany(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = New(adapter)
+		users        = []User{{ID: 10}, {ID: 20}}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+			{ID: 15, BuyerID: 20},
+			{ID: 20, BuyerID: 20},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Times(4)
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
+	cur.MockScan(transactions[3].ID, transactions[3].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &users, "transactions"))
+	assert.Equal(t, transactions[:2], users[0].Transactions)
+	assert.Equal(t, transactions[2:], users[1].Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nestedHasMany(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = New(adapter)
+		address      = Address{User: &User{ID: 10}}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+		}
+
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user.transactions"))
+	assert.Equal(t, transactions, address.User.Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nestedNullHasMany(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		address = Address{User: nil}
+	)
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user.transactions"))
+	assert.Nil(t, address.User)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nestedSliceHasMany(t *testing.T) {
+	var (
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		addresses = []Address{
+			{User: &User{ID: 10}},
+			{User: &User{ID: 20}},
+		}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+			{ID: 15, BuyerID: 20},
+			{ID: 20, BuyerID: 20},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10, 20))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("transactions").Where(In("user_id", 20, 10))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Times(4)
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
+	cur.MockScan(transactions[3].ID, transactions[3].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
+	assert.Equal(t, transactions[:2], addresses[0].User.Transactions)
+	assert.Equal(t, transactions[2:], addresses[1].User.Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nestedNullSliceHasMany(t *testing.T) {
+	var (
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		addresses = []Address{
+			{User: &User{ID: 10}},
+			{User: nil},
+			{User: &User{ID: 15}},
+		}
+		transactions = []Transaction{
+			{ID: 5, BuyerID: 10},
+			{ID: 10, BuyerID: 10},
+			{ID: 15, BuyerID: 15},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("transactions").Where(In("user_id", 10, 15))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("transactions").Where(In("user_id", 15, 10))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "user_id"}, nil).Once()
+	cur.On("Next").Return(true).Times(3)
+	cur.MockScan(transactions[0].ID, transactions[0].BuyerID).Twice()
+	cur.MockScan(transactions[1].ID, transactions[1].BuyerID).Twice()
+	cur.MockScan(transactions[2].ID, transactions[2].BuyerID).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
+	assert.Equal(t, transactions[:2], addresses[0].User.Transactions)
+	assert.Nil(t, addresses[1].User)
+	assert.Equal(t, transactions[2:], addresses[2].User.Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_belongsTo(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = New(adapter)
+		user        = User{ID: 10, Name: "Del Piero"}
+		transaction = Transaction{BuyerID: 10}
+		cur         = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(user.ID, user.Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transaction, "buyer"))
+	assert.Equal(t, user, transaction.Buyer)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_ptrBelongsTo(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		user    = User{ID: 10, Name: "Del Piero"}
+		address = Address{UserID: &user.ID}
+		cur     = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(user.ID, user.Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
+	assert.Equal(t, user, *address.User)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_nullBelongsTo(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		address = Address{}
+	)
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
+	assert.Nil(t, address.User)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_ptrBelongsTo_notFound_null(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		userID  = 10
+		address = Address{UserID: &userID}
+		cur     = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
+	assert.Nil(t, address.User)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_sliceBelongsTo(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = New(adapter)
+		transactions = []Transaction{
+			{BuyerID: 10},
+			{BuyerID: 20},
+		}
+		users = []User{
+			{ID: 10, Name: "Del Piero"},
+			{ID: 20, Name: "Nedved"},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10, 20))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("users").Where(In("id", 20, 10))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(users[0].ID, users[0].Name).Twice()
+	cur.MockScan(users[1].ID, users[1].Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &transactions, "buyer"))
+	assert.Equal(t, users[0], transactions[0].Buyer)
+	assert.Equal(t, users[1], transactions[1].Buyer)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_ptrSliceBelongsTo(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		users   = []User{
+			{ID: 10, Name: "Del Piero"},
+			{ID: 20, Name: "Nedved"},
+		}
+		addresses = []Address{
+			{UserID: &users[0].ID},
+			{UserID: &users[1].ID},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10, 20))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("users").Where(In("id", 20, 10))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Twice()
+	cur.MockScan(users[0].ID, users[0].Name).Twice()
+	cur.MockScan(users[1].ID, users[1].Name).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user"))
+	assert.Equal(t, users[0], *addresses[0].User)
+	assert.Equal(t, users[1], *addresses[1].User)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_ptrSliceBelongsTo_notFound_null(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		users   = []User{
+			{ID: 10, Name: "Del Piero"},
+			{ID: 20, Name: "Nedved"},
+		}
+		addresses = []Address{
+			{UserID: &users[0].ID},
+			{UserID: &users[1].ID},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10, 20))).Return(cur, nil).Maybe()
+	adapter.On("Query", From("users").Where(In("id", 20, 10))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user"))
+	assert.Nil(t, addresses[0].User)
+	assert.Nil(t, addresses[1].User)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_sliceNestedBelongsTo(t *testing.T) {
+	var (
+		adapter      = &testAdapter{}
+		repo         = New(adapter)
+		address      = Address{ID: 10, Street: "Continassa"}
+		transactions = []Transaction{
+			{AddressID: 10},
+		}
+		users = []User{
+			{ID: 10, Name: "Del Piero", Transactions: transactions},
+			{ID: 20, Name: "Nedved"},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("user_addresses").Where(In("id", 10).AndNil("deleted_at"))).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "street"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(address.ID, address.Street).Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &users, "transactions.address"))
+	assert.Equal(t, []Transaction{
+		{AddressID: 10, Address: address},
+	}, users[0].Transactions)
+	assert.Equal(t, []Transaction{}, users[1].Transactions)
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Preload_alreadyLoaded(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		userID  = 1
+		address = Address{
+			UserID: &userID,
+			User:   &User{ID: userID},
+		}
+	)
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_alreadyLoadedForceReload(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		userID  = 1
+		address = Address{
+			UserID: &userID,
+			User:   &User{ID: userID},
+		}
+		cur = &testCursor{}
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 1)).Reload()).Return(cur, nil).Maybe()
+
+	cur.On("Close").Return(nil).Once()
+	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
+	cur.On("Next").Return(true).Once()
+	cur.MockScan(userID, "Del Piero").Twice()
+	cur.On("Next").Return(false).Once()
+
+	assert.Nil(t, repo.Preload(context.TODO(), &address, "user", Reload(true)))
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Preload_emptySlice(t *testing.T) {
+	var (
+		adapter   = &testAdapter{}
+		repo      = New(adapter)
+		addresses = []Address{}
+	)
+
+	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
+}
+
+func TestQuery_Preload_notPointerPanic(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = New(adapter)
+		transaction = Transaction{}
+	)
+
+	assert.Panics(t, func() { repo.Preload(context.TODO(), transaction, "User") })
+}
+
+func TestRepository_Preload_queryError(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = New(adapter)
+		transaction = Transaction{BuyerID: 10}
+		cur         = &testCursor{}
+		err         = errors.New("error")
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, err).Once()
+
+	assert.Equal(t, err, repo.Preload(context.TODO(), &transaction, "buyer"))
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_MustPreload(t *testing.T) {
+	var (
+		adapter     = &testAdapter{}
+		repo        = New(adapter)
+		transaction = Transaction{BuyerID: 10}
+		cur         = createCursor(0)
+	)
+
+	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+
+	assert.NotPanics(t, func() {
+		repo.MustPreload(context.TODO(), &transaction, "buyer")
+	})
+
+	adapter.AssertExpectations(t)
+	cur.AssertExpectations(t)
+}
+
+func TestRepository_Exec(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = "UPDATE users SET something = ? WHERE something2 = ?;"
+		args    = []interface{}{3, "sdfds"}
+		rets    = []interface{}{1, 2, nil}
+	)
+
+	adapter.On("Exec", context.TODO(), query, args).Return(rets...).Once()
+
+	lastInsertedId, rowsAffected, err := repo.Exec(context.TODO(), query, args...)
+	assert.Equal(t, rets[0], lastInsertedId)
+	assert.Equal(t, rets[1], rowsAffected)
+	assert.Equal(t, rets[2], err)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_MustExec(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+		query   = "UPDATE users SET something = ? WHERE something2 = ?;"
+		args    = []interface{}{3, "sdfds"}
+		rets    = []interface{}{1, 2, nil}
+	)
+
+	adapter.On("Exec", context.TODO(), query, args).Return(rets...).Once()
+
+	assert.NotPanics(t, func() {
+		lastInsertedId, rowsAffected := repo.MustExec(context.TODO(), query, args...)
+		assert.Equal(t, rets[0], lastInsertedId)
+		assert.Equal(t, rets[1], rowsAffected)
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Once()
+
+	repo := New(adapter)
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return nil
+	})
+
+	assert.Nil(t, err)
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_beginError(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(errors.New("error")).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		// doing good things
+		return nil
+	})
+
+	assert.Equal(t, errors.New("error"), err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_commitError(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Commit").Return(errors.New("error")).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		// doing good things
+		return nil
+	})
+
+	assert.Equal(t, errors.New("error"), err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_returnErrorAndRollback(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		// doing good things
+		return errors.New("error")
+	})
+
+	assert.Equal(t, errors.New("error"), err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_panicWithErrorAndRollback(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		// doing good things
+		panic(errors.New("error"))
+	})
+
+	assert.Equal(t, errors.New("error"), err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_panicWithStringAndRollback(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	assert.Panics(t, func() {
+		_ = New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+			// doing good things
+			panic("error")
+		})
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_runtimeError(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	var user *User
+	assert.Panics(t, func() {
+		_ = New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+			_ = user.ID
+			return nil
+		})
+	})
+
+	adapter.AssertExpectations(t)
+}
+
+type testTransactionAdapter struct {
+	*testAdapter
+}
+
+func (tta testTransactionAdapter) BeginTransaction(ctx context.Context, options TransactionOptions) (Adapter, error) {
+	args := tta.Called(options)
+	return tta, args.Error(0)
+}
+
+func TestRepository_Transaction_options(t *testing.T) {
+	adapter := testTransactionAdapter{testAdapter: &testAdapter{}}
+	adapter.On("BeginTransaction", TransactionOptions{Isolation: Serializable, ReadOnly: true}).Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		return nil
+	}, Isolation(Serializable), ReadOnly(true))
+
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_optionsWithoutOptions(t *testing.T) {
+	adapter := testTransactionAdapter{testAdapter: &testAdapter{}}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		return nil
+	}, ReadOnly(false))
+
+	assert.Nil(t, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_optionsNotSupported(t *testing.T) {
+	adapter := &testAdapter{}
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		return nil
+	}, DeferConstraints(true))
+
+	assert.Equal(t, ErrUnsupportedTransactionOptions, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_nestedOptions(t *testing.T) {
+	var (
+		adapter = testTransactionAdapter{testAdapter: &testAdapter{}}
+		repo    = New(adapter)
+	)
+
+	adapter.On("BeginTransaction", TransactionOptions{Isolation: Serializable}).Return(nil).Once()
+	adapter.On("Begin").Return(nil).Twice()
+	adapter.On("Commit").Return(nil).Twice()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+			return nil
+		}))
+
+		assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+			return nil
+		}, Isolation(Serializable)))
+
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			return nil
+		}, Isolation(Serializable), ReadOnly(true))
+	}, Isolation(Serializable))
+
+	assert.Equal(t, ErrNestedTransactionOptions, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retry(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		calls   = 0
+	)
+
+	adapter.On("Begin").Return(nil).Times(3)
+	adapter.On("Rollback").Return(nil).Once()
+	adapter.On("Commit").Return(ErrSerializationFailure).Once()
+	adapter.On("Commit").Return(nil).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return RetryableError{Type: Deadlock, Err: errors.New("deadlock")}
+		}
+
+		return nil
+	}, Retry(3, time.Millisecond))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+	adapter.AssertExpectations(t)
+}
 
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "street"}, nil).Once()
-	cur.On("Next").Return(true).Once()
-	cur.MockScan(address.ID, address.Street).Twice()
-	cur.On("Next").Return(false).Once()
+func TestRepository_Transaction_retryExceeded(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).Twice()
+	adapter.On("Rollback").Return(nil).Twice()
 
-	assert.Nil(t, repo.Preload(context.TODO(), &users, "transactions.address"))
-	assert.Equal(t, []Transaction{
-		{AddressID: 10, Address: address},
-	}, users[0].Transactions)
-	assert.Equal(t, []Transaction{}, users[1].Transactions)
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		return ErrDeadlock
+	}, Retry(1, time.Millisecond))
 
+	assert.Equal(t, ErrDeadlock, err)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Preload_alreadyLoaded(t *testing.T) {
+func TestRepository_Transaction_retryNotRetryable(t *testing.T) {
+	adapter := &testAdapter{}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
+
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		return ErrUniqueConstraint
+	}, Retry(3, time.Millisecond))
+
+	assert.Equal(t, ErrUniqueConstraint, err)
+	adapter.AssertExpectations(t)
+}
+
+func TestRepository_Transaction_retryNested(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		userID  = 1
-		address = Address{
-			UserID: &userID,
-			User:   &User{ID: userID},
-		}
+		calls   = 0
 	)
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user"))
+	adapter.On("Begin").Return(nil).Twice()
+	adapter.On("Rollback").Return(nil).Twice()
+
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			calls++
+			return ErrDeadlock
+		}, Retry(3, time.Millisecond))
+	})
+
+	assert.Equal(t, ErrDeadlock, err)
+	assert.Equal(t, 1, calls)
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Preload_alreadyLoadedForceReload(t *testing.T) {
+func TestRepository_Transaction_afterCommit(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		userID  = 1
-		address = Address{
-			UserID: &userID,
-			User:   &User{ID: userID},
-		}
-		cur = &testCursor{}
+		calls   []string
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 1)).Reload()).Return(cur, nil).Maybe()
+	adapter.On("Begin").Return(nil).Twice()
+	adapter.On("Commit").Return(nil).Twice()
 
-	cur.On("Close").Return(nil).Once()
-	cur.On("Fields").Return([]string{"id", "name"}, nil).Once()
-	cur.On("Next").Return(true).Once()
-	cur.MockScan(userID, "Del Piero").Twice()
-	cur.On("Next").Return(false).Once()
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		AfterCommit(ctx, func(ctx context.Context) {
+			calls = append(calls, "outer")
+		})
+		AfterRollback(ctx, func(ctx context.Context) {
+			calls = append(calls, "rollback")
+		})
 
-	assert.Nil(t, repo.Preload(context.TODO(), &address, "user", Reload(true)))
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) {
+				// callback uses context outside of transaction.
+				assert.Nil(t, ctx.Value(ctxKey))
+				calls = append(calls, "nested")
+			})
+
+			assert.Nil(t, calls)
+			return nil
+		})
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"outer", "nested"}, calls)
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Preload_emptySlice(t *testing.T) {
+func TestRepository_Transaction_afterRollback(t *testing.T) {
 	var (
-		adapter   = &testAdapter{}
-		repo      = New(adapter)
-		addresses = []Address{}
+		adapter = &testAdapter{}
+		calls   []string
 	)
 
-	assert.Nil(t, repo.Preload(context.TODO(), &addresses, "user.transactions"))
-}
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
-func TestQuery_Preload_notPointerPanic(t *testing.T) {
-	var (
-		adapter     = &testAdapter{}
-		repo        = New(adapter)
-		transaction = Transaction{}
-	)
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		AfterCommit(ctx, func(ctx context.Context) {
+			calls = append(calls, "commit")
+		})
+		AfterRollback(ctx, func(ctx context.Context) {
+			calls = append(calls, "rollback")
+		})
 
-	assert.Panics(t, func() { repo.Preload(context.TODO(), transaction, "User") })
+		return errors.New("error")
+	})
+
+	assert.Equal(t, errors.New("error"), err)
+	assert.Equal(t, []string{"rollback"}, calls)
+	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Preload_queryError(t *testing.T) {
+func TestRepository_Transaction_afterRollbackCommitError(t *testing.T) {
 	var (
-		adapter     = &testAdapter{}
-		repo        = New(adapter)
-		transaction = Transaction{BuyerID: 10}
-		cur         = &testCursor{}
-		err         = errors.New("error")
+		adapter = &testAdapter{}
+		calls   []string
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, err).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Commit").Return(errors.New("error")).Once()
 
-	assert.Equal(t, err, repo.Preload(context.TODO(), &transaction, "buyer"))
+	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+		AfterRollback(ctx, func(ctx context.Context) {
+			calls = append(calls, "rollback")
+		})
+
+		return nil
+	})
 
+	assert.Equal(t, errors.New("error"), err)
+	assert.Equal(t, []string{"rollback"}, calls)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_MustPreload(t *testing.T) {
+func TestRepository_Transaction_afterRollbackPanic(t *testing.T) {
 	var (
-		adapter     = &testAdapter{}
-		repo        = New(adapter)
-		transaction = Transaction{BuyerID: 10}
-		cur         = createCursor(0)
+		adapter = &testAdapter{}
+		calls   []string
 	)
 
-	adapter.On("Query", From("users").Where(In("id", 10))).Return(cur, nil).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Rollback").Return(nil).Once()
 
-	assert.NotPanics(t, func() {
-		repo.MustPreload(context.TODO(), &transaction, "buyer")
+	assert.Panics(t, func() {
+		_ = New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
+			AfterRollback(ctx, func(ctx context.Context) {
+				calls = append(calls, "rollback")
+			})
+
+			panic("error")
+		})
 	})
 
+	assert.Equal(t, []string{"rollback"}, calls)
 	adapter.AssertExpectations(t)
-	cur.AssertExpectations(t)
 }
 
-func TestRepository_Exec(t *testing.T) {
+func TestRepository_Transaction_afterCommitNestedRollback(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
 		repo    = New(adapter)
-		query   = "UPDATE users SET something = ? WHERE something2 = ?;"
-		args    = []interface{}{3, "sdfds"}
-		rets    = []interface{}{1, 2, nil}
+		calls   []string
 	)
 
-	adapter.On("Exec", context.TODO(), query, args).Return(rets...).Once()
+	adapter.On("Begin").Return(nil).Times(3)
+	adapter.On("Commit").Return(nil).Twice()
+	adapter.On("Rollback").Return(nil).Once()
 
-	lastInsertedId, rowsAffected, err := repo.Exec(context.TODO(), query, args...)
-	assert.Equal(t, rets[0], lastInsertedId)
-	assert.Equal(t, rets[1], rowsAffected)
-	assert.Equal(t, rets[2], err)
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		assert.Nil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) {
+				calls = append(calls, "committed nested")
+			})
+			return nil
+		}))
+
+		assert.NotNil(t, repo.Transaction(ctx, func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) {
+				calls = append(calls, "rolled back nested commit")
+			})
+			AfterRollback(ctx, func(ctx context.Context) {
+				calls = append(calls, "rolled back nested rollback")
+			})
+			return errors.New("error")
+		}))
+
+		assert.Nil(t, calls)
+		return nil
+	})
 
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"committed nested", "rolled back nested rollback"}, calls)
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_MustExec(t *testing.T) {
+func TestAfterCommit_outsideTransaction(t *testing.T) {
+	var (
+		calls []string
+	)
+
+	AfterCommit(context.TODO(), func(ctx context.Context) {
+		calls = append(calls, "commit")
+	})
+	AfterRollback(context.TODO(), func(ctx context.Context) {
+		calls = append(calls, "rollback")
+	})
+
+	assert.Equal(t, []string{"commit", "rollback"}, calls)
+}
+
+func TestAfterCommit_withAdapter(t *testing.T) {
 	var (
 		adapter = &testAdapter{}
-		repo    = New(adapter)
-		query   = "UPDATE users SET something = ? WHERE something2 = ?;"
-		args    = []interface{}{3, "sdfds"}
-		rets    = []interface{}{1, 2, nil}
+		ctx     = WithAdapter(context.TODO(), adapter)
+		calls   []string
 	)
 
-	adapter.On("Exec", context.TODO(), query, args).Return(rets...).Once()
+	adapter.On("Begin").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
 
-	assert.NotPanics(t, func() {
-		lastInsertedId, rowsAffected := repo.MustExec(context.TODO(), query, args...)
-		assert.Equal(t, rets[0], lastInsertedId)
-		assert.Equal(t, rets[1], rowsAffected)
+	AfterCommit(ctx, func(ctx context.Context) {
+		calls = append(calls, "commit")
+	})
+
+	err := New(adapter).Transaction(ctx, func(ctx context.Context) error {
+		AfterCommit(ctx, func(ctx context.Context) {
+			calls = append(calls, "nested")
+		})
+
+		return nil
 	})
 
+	assert.Nil(t, err)
+	assert.Nil(t, calls)
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Transaction(t *testing.T) {
-	adapter := &testAdapter{}
-	adapter.On("Begin").Return(nil).On("Commit").Return(nil).Once()
+type testSavepointAdapter struct {
+	*testAdapter
+}
 
-	repo := New(adapter)
+func (tsa testSavepointAdapter) Begin(ctx context.Context) (Adapter, error) {
+	_, err := tsa.testAdapter.Begin(ctx)
+	return tsa, err
+}
 
-	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+func (tsa testSavepointAdapter) Savepoint(ctx context.Context, name string) error {
+	args := tsa.Called(name)
+	return args.Error(0)
+}
+
+func (tsa testSavepointAdapter) RollbackTo(ctx context.Context, name string) error {
+	args := tsa.Called(name)
+	return args.Error(0)
+}
+
+func (tsa testSavepointAdapter) Release(ctx context.Context, name string) error {
+	args := tsa.Called(name)
+	return args.Error(0)
+}
+
+func TestRepository_Savepoint(t *testing.T) {
+	var (
+		adapter = testSavepointAdapter{testAdapter: &testAdapter{}}
+		ctx     = WithAdapter(context.TODO(), adapter)
+	)
+
+	adapter.On("Savepoint", "step").Return(nil).Once()
+	adapter.On("Release", "step").Return(nil).Once()
+
+	err := New(adapter).Savepoint(ctx, "step", func(ctx context.Context) error {
 		return nil
 	})
 
 	assert.Nil(t, err)
-
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Transaction_beginError(t *testing.T) {
-	adapter := &testAdapter{}
-	adapter.On("Begin").Return(errors.New("error")).Once()
+func TestRepository_Savepoint_savepointError(t *testing.T) {
+	var (
+		adapter = testSavepointAdapter{testAdapter: &testAdapter{}}
+		ctx     = WithAdapter(context.TODO(), adapter)
+	)
 
-	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
-		// doing good things
-		return nil
+	adapter.On("Savepoint", "step").Return(errors.New("error")).Once()
+
+	err := New(adapter).Savepoint(ctx, "step", func(ctx context.Context) error {
+		panic("should not be called")
 	})
 
 	assert.Equal(t, errors.New("error"), err)
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Transaction_commitError(t *testing.T) {
-	adapter := &testAdapter{}
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Commit").Return(errors.New("error")).Once()
+func TestRepository_Savepoint_rollbackTo(t *testing.T) {
+	var (
+		adapter = testSavepointAdapter{testAdapter: &testAdapter{}}
+		ctx     = WithAdapter(context.TODO(), adapter)
+	)
 
-	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
-		// doing good things
-		return nil
+	adapter.On("Savepoint", "step").Return(nil).Once()
+	adapter.On("RollbackTo", "step").Return(nil).Once()
+
+	err := New(adapter).Savepoint(ctx, "step", func(ctx context.Context) error {
+		return errors.New("error")
 	})
 
 	assert.Equal(t, errors.New("error"), err)
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Transaction_returnErrorAndRollback(t *testing.T) {
-	adapter := &testAdapter{}
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Rollback").Return(nil).Once()
+func TestRepository_Savepoint_panic(t *testing.T) {
+	var (
+		adapter = testSavepointAdapter{testAdapter: &testAdapter{}}
+		ctx     = WithAdapter(context.TODO(), adapter)
+	)
 
-	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
-		// doing good things
-		return errors.New("error")
+	adapter.On("Savepoint", "step").Return(nil).Once()
+	adapter.On("RollbackTo", "step").Return(nil).Once()
+
+	assert.Panics(t, func() {
+		_ = New(adapter).Savepoint(ctx, "step", func(ctx context.Context) error {
+			panic("error")
+		})
 	})
 
-	assert.Equal(t, errors.New("error"), err)
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Transaction_panicWithErrorAndRollback(t *testing.T) {
-	adapter := &testAdapter{}
+func TestRepository_Savepoint_afterCommit(t *testing.T) {
+	var (
+		adapter = testSavepointAdapter{testAdapter: &testAdapter{}}
+		repo    = New(adapter)
+		calls   []string
+	)
+
 	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Rollback").Return(nil).Once()
+	adapter.On("Savepoint", "released").Return(nil).Once()
+	adapter.On("Release", "released").Return(nil).Once()
+	adapter.On("Savepoint", "rolled back").Return(nil).Once()
+	adapter.On("RollbackTo", "rolled back").Return(nil).Once()
+	adapter.On("Commit").Return(nil).Once()
 
-	err := New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
-		// doing good things
-		panic(errors.New("error"))
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		assert.Nil(t, repo.Savepoint(ctx, "released", func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) {
+				calls = append(calls, "released commit")
+			})
+			return nil
+		}))
+
+		assert.NotNil(t, repo.Savepoint(ctx, "rolled back", func(ctx context.Context) error {
+			AfterCommit(ctx, func(ctx context.Context) {
+				calls = append(calls, "rolled back commit")
+			})
+			AfterRollback(ctx, func(ctx context.Context) {
+				calls = append(calls, "rolled back rollback")
+			})
+			return errors.New("error")
+		}))
+
+		assert.Nil(t, calls)
+		return nil
 	})
 
-	assert.Equal(t, errors.New("error"), err)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"released commit", "rolled back rollback"}, calls)
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Transaction_panicWithStringAndRollback(t *testing.T) {
-	adapter := &testAdapter{}
-	adapter.On("Begin").Return(nil).Once()
-	adapter.On("Rollback").Return(nil).Once()
+func TestRepository_Savepoint_outsideTransaction(t *testing.T) {
+	adapter := testSavepointAdapter{testAdapter: &testAdapter{}}
 
-	assert.Panics(t, func() {
-		_ = New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
-			// doing good things
-			panic("error")
-		})
+	err := New(adapter).Savepoint(context.TODO(), "step", func(ctx context.Context) error {
+		return nil
 	})
 
+	assert.Equal(t, ErrSavepointOutsideTransaction, err)
 	adapter.AssertExpectations(t)
 }
 
-func TestRepository_Transaction_runtimeError(t *testing.T) {
-	adapter := &testAdapter{}
+func TestRepository_Savepoint_unsupported(t *testing.T) {
+	var (
+		adapter = &testAdapter{}
+		repo    = New(adapter)
+	)
+
 	adapter.On("Begin").Return(nil).Once()
 	adapter.On("Rollback").Return(nil).Once()
 
-	var user *User
-	assert.Panics(t, func() {
-		_ = New(adapter).Transaction(context.TODO(), func(ctx context.Context) error {
-			_ = user.ID
+	err := repo.Transaction(context.TODO(), func(ctx context.Context) error {
+		return repo.Savepoint(ctx, "step", func(ctx context.Context) error {
 			return nil
 		})
 	})
 
+	assert.Equal(t, ErrUnsupportedSavepoint, err)
 	adapter.AssertExpectations(t)
 }
+
+func TestCurrentTime(t *testing.T) {
+	var (
+		now   = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock = func() time.Time { return now }
+	)
+
+	assert.Equal(t, now, CurrentTime(New(&testAdapter{}, WithClock(clock))))
+	assert.Equal(t, Now(), CurrentTime(New(&testAdapter{})))
+	assert.Equal(t, Now(), CurrentTime(nil))
+}
@@ -1,6 +1,9 @@
 package rel
 
-import "strings"
+import (
+	"errors"
+	"strings"
+)
 
 // SchemaOp type.
 type SchemaOp uint8
@@ -24,11 +27,13 @@ func (s SchemaOp) String() string {
 type Migration interface {
 	internalMigration()
 	description() string
+	detail() string
 }
 
 // Schema builder.
 type Schema struct {
-	Migrations []Migration
+	Migrations      []Migration
+	skipTransaction bool
 }
 
 func (s *Schema) add(migration Migration) {
@@ -49,6 +54,18 @@ func (s *Schema) CreateTableIfNotExists(name string, fn func(t *Table), options
 	s.add(table)
 }
 
+// CreateVersionTable creates <name>_versions table used to store version history of records in name table.
+// The definition should be the same as the one used to create name table,
+// columns are copied without primary, unique and foreign keys, and valid_from and valid_to columns are added.
+func (s *Schema) CreateVersionTable(name string, fn func(t *Table), options ...TableOption) {
+	base := createTable(name, nil)
+	fn(&base)
+
+	table, primaries := createVersionTable(versionTable(name), base, options)
+	s.add(table)
+	s.add(createIndex(table.Name, table.Name+"_range", append(primaries, "valid_to"), nil))
+}
+
 // AlterTable with name and its definition.
 func (s *Schema) AlterTable(name string, fn func(t *AlterTable), options ...TableOption) {
 	table := alterTable(name, options)
@@ -117,6 +134,29 @@ func (s *Schema) Do(fn Do) {
 	s.add(fn)
 }
 
+// Describe returns detailed human readable representation of each migration.
+func (s Schema) Describe() []string {
+	details := make([]string, len(s.Migrations))
+	for i := range details {
+		details[i] = s.Migrations[i].detail()
+	}
+
+	return details
+}
+
+// NoTransaction marks schema to be executed without transaction.
+// This is useful for statements that can't be run inside a transaction, such as creating index concurrently.
+// It's honored by migrator package, which is also used by rel cli,
+// other migrator needs to check Transactional before running the schema.
+func (s *Schema) NoTransaction() {
+	s.skipTransaction = true
+}
+
+// Transactional returns true if schema should be executed inside a transaction.
+func (s Schema) Transactional() bool {
+	return !s.skipTransaction
+}
+
 // String returns schema operation.
 func (s Schema) String() string {
 	descs := make([]string, len(s.Migrations))
@@ -127,6 +167,45 @@ func (s Schema) String() string {
 	return strings.Join(descs, ", ")
 }
 
+// Reverse returns schema that reverts every migrations in reverse order.
+// It returns error when schema contains migration that can't be reverted, such as Raw, Do or any drop operation.
+func (s Schema) Reverse() (Schema, error) {
+	var (
+		reversed = Schema{
+			Migrations:      make([]Migration, 0, len(s.Migrations)),
+			skipTransaction: s.skipTransaction,
+		}
+	)
+
+	for i := len(s.Migrations) - 1; i >= 0; i-- {
+		var (
+			migration Migration
+			err       error
+		)
+
+		switch m := s.Migrations[i].(type) {
+		case Table:
+			migration, err = m.reverse()
+		case Index:
+			migration, err = m.reverse()
+		default:
+			err = irreversibleError(m.description())
+		}
+
+		if err != nil {
+			return Schema{}, err
+		}
+
+		reversed.add(migration)
+	}
+
+	return reversed, nil
+}
+
+func irreversibleError(description string) error {
+	return errors.New("rel: irreversible migration: " + description)
+}
+
 // Raw string
 type Raw string
 
@@ -134,6 +213,10 @@ func (r Raw) description() string {
 	return "execute raw command"
 }
 
+func (r Raw) detail() string {
+	return r.description() + ": " + string(r)
+}
+
 func (r Raw) internalMigration()       {}
 func (r Raw) internalTableDefinition() {}
 
@@ -144,4 +227,8 @@ func (d Do) description() string {
 	return "run go code"
 }
 
+func (d Do) detail() string {
+	return d.description()
+}
+
 func (d Do) internalMigration() {}
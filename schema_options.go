@@ -1,5 +1,9 @@
 package rel
 
+import (
+	"strings"
+)
+
 // TableOption interface.
 // Available options are: Comment, Options.
 type TableOption interface {
@@ -140,3 +144,14 @@ func (o Optional) applyTable(table *Table) {
 func (o Optional) applyIndex(index *Index) {
 	index.Optional = bool(o)
 }
+
+func writeFlag(buffer *strings.Builder, enabled bool, flag string) {
+	if enabled {
+		buffer.WriteString(" ")
+		buffer.WriteString(flag)
+	}
+}
+
+func writeOptions(buffer *strings.Builder, options string) {
+	writeFlag(buffer, options != "", options)
+}
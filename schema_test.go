@@ -1,6 +1,7 @@
 package rel
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,6 +55,61 @@ func TestSchema_CreateTable(t *testing.T) {
 	assert.Equal(t, "create table products, create table wishlists", schema.String())
 }
 
+func TestSchema_CreateVersionTable(t *testing.T) {
+	var schema Schema
+
+	schema.CreateVersionTable("products", func(t *Table) {
+		t.ID("id")
+		t.String("sku", Unique(true))
+		t.Int("store_id")
+		t.ForeignKey("store_id", "stores", "id")
+		t.DateTime("updated_at")
+	})
+
+	assert.Equal(t, Table{
+		Op:   SchemaCreate,
+		Name: "products_versions",
+		Definitions: []TableDefinition{
+			Column{Name: "id", Type: Int, Unsigned: true},
+			Column{Name: "sku", Type: String},
+			Column{Name: "store_id", Type: Int},
+			Column{Name: "updated_at", Type: DateTime},
+			Column{Name: "valid_from", Type: DateTime},
+			Column{Name: "valid_to", Type: DateTime},
+		},
+	}, schema.Migrations[0])
+
+	assert.Equal(t, Index{
+		Table:   "products_versions",
+		Name:    "products_versions_range",
+		Columns: []string{"id", "valid_to"},
+		Op:      SchemaCreate,
+	}, schema.Migrations[1])
+}
+
+func TestSchema_CreateVersionTable_compositePrimaryKey(t *testing.T) {
+	var schema Schema
+
+	schema.CreateVersionTable("user_roles", func(t *Table) {
+		t.BigInt("user_id")
+		t.BigInt("role_id")
+		t.PrimaryKeys([]string{"user_id", "role_id"})
+	})
+
+	assert.Equal(t, Table{
+		Op:   SchemaCreate,
+		Name: "user_roles_versions",
+		Definitions: []TableDefinition{
+			Column{Name: "user_id", Type: BigInt},
+			Column{Name: "role_id", Type: BigInt},
+			Column{Name: "valid_from", Type: DateTime},
+			Column{Name: "valid_to", Type: DateTime},
+		},
+	}, schema.Migrations[0])
+
+	assert.Equal(t, []string{"user_id", "role_id", "valid_to"}, schema.Migrations[1].(Index).Columns)
+}
+
 func TestSchema_AlterTable(t *testing.T) {
 	var schema Schema
 
@@ -197,6 +253,174 @@ func TestSchema_DropIndex(t *testing.T) {
 	}, schema.Migrations[0])
 }
 
+func TestSchema_Reverse(t *testing.T) {
+	var schema Schema
+
+	schema.CreateTable("users", func(t *Table) {
+		t.ID("id")
+	})
+	schema.CreateTableIfNotExists("logs", func(t *Table) {
+		t.ID("id")
+	})
+	schema.AlterTable("users", func(t *AlterTable) {
+		t.Bool("verified")
+		t.RenameColumn("name", "fullname")
+	})
+	schema.AddColumn("users", "age", Int)
+	schema.RenameColumn("users", "email", "primary_email")
+	schema.RenameTable("trxs", "transactions")
+	schema.CreateIndex("users", "age_idx", []string{"age"})
+
+	reversed, err := schema.Reverse()
+	assert.Nil(t, err)
+	assert.Equal(t, []Migration{
+		Index{Op: SchemaDrop, Table: "users", Name: "age_idx"},
+		Table{Op: SchemaRename, Name: "transactions", Rename: "trxs"},
+		Table{
+			Op:   SchemaAlter,
+			Name: "users",
+			Definitions: []TableDefinition{
+				Column{Op: SchemaRename, Name: "primary_email", Rename: "email"},
+			},
+		},
+		Table{
+			Op:   SchemaAlter,
+			Name: "users",
+			Definitions: []TableDefinition{
+				Column{Op: SchemaDrop, Name: "age"},
+			},
+		},
+		Table{
+			Op:   SchemaAlter,
+			Name: "users",
+			Definitions: []TableDefinition{
+				Column{Op: SchemaRename, Name: "fullname", Rename: "name"},
+				Column{Op: SchemaDrop, Name: "verified"},
+			},
+		},
+		Table{Op: SchemaDrop, Name: "logs", Optional: true},
+		Table{Op: SchemaDrop, Name: "users"},
+	}, reversed.Migrations)
+}
+
+func TestSchema_Reverse_noTransaction(t *testing.T) {
+	var schema Schema
+
+	schema.NoTransaction()
+	schema.CreateIndex("users", "age_idx", []string{"age"}, Options("CONCURRENTLY"))
+
+	reversed, err := schema.Reverse()
+	assert.Nil(t, err)
+	assert.False(t, reversed.Transactional())
+}
+
+func TestSchema_Reverse_irreversible(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(schema *Schema)
+		err  error
+	}{
+		{
+			name: "drop table",
+			fn:   func(schema *Schema) { schema.DropTable("users") },
+			err:  errors.New("rel: irreversible migration: drop table users"),
+		},
+		{
+			name: "drop column",
+			fn:   func(schema *Schema) { schema.DropColumn("users", "name") },
+			err:  errors.New("rel: irreversible migration: drop column name on users"),
+		},
+		{
+			name: "fragment",
+			fn: func(schema *Schema) {
+				schema.AlterTable("users", func(t *AlterTable) { t.Fragment("ADD CHECK (age > 0)") })
+			},
+			err: errors.New("rel: irreversible migration: alter table users definition"),
+		},
+		{
+			name: "drop index",
+			fn:   func(schema *Schema) { schema.DropIndex("users", "age_idx") },
+			err:  errors.New("rel: irreversible migration: drop index age_idx on users"),
+		},
+		{
+			name: "raw",
+			fn:   func(schema *Schema) { schema.Exec("RAW SQL") },
+			err:  errors.New("rel: irreversible migration: execute raw command"),
+		},
+		{
+			name: "do",
+			fn:   func(schema *Schema) { schema.Do(func(repo Repository) error { return nil }) },
+			err:  errors.New("rel: irreversible migration: run go code"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var schema Schema
+
+			schema.CreateTable("products", func(t *Table) {
+				t.ID("id")
+			})
+			test.fn(&schema)
+
+			reversed, err := schema.Reverse()
+			assert.Equal(t, test.err, err)
+			assert.Nil(t, reversed.Migrations)
+		})
+	}
+}
+
+func TestSchema_Describe(t *testing.T) {
+	var schema Schema
+
+	schema.CreateTable("users", func(t *Table) {
+		t.ID("id")
+		t.String("name", Limit(100), Required(true), Default(""))
+		t.Decimal("balance", Precision(10), Scale(2), Unsigned(true))
+		t.Int("address_id")
+		t.ForeignKey("address_id", "addresses", "id", OnDelete("CASCADE"))
+		t.Unique([]string{"name"}, Name("name_unique"))
+		t.Fragment("CHECK (balance >= 0)")
+	}, Options("ENGINE=InnoDB"))
+	schema.CreateTableIfNotExists("logs", func(t *Table) {
+		t.Int("user_id", Unique(true))
+		t.Text("message")
+		t.PrimaryKeys([]string{"user_id"})
+	})
+	schema.AlterTable("users", func(t *AlterTable) {
+		t.Bool("verified")
+		t.RenameColumn("name", "fullname")
+		t.DropColumn("address_id")
+	})
+	schema.RenameTable("trxs", "transactions")
+	schema.DropTableIfExists("logs")
+	schema.CreateUniqueIndex("users", "fullname_idx", []string{"fullname", "verified"}, Eq("verified", true))
+	schema.DropIndex("users", "fullname_idx", Optional(true))
+	schema.Exec("UPDATE users SET verified=true;")
+	schema.Do(func(repo Repository) error { return nil })
+
+	assert.Equal(t, []string{
+		"create table users (id ID primary, name STRING required limit 100 default \"\", balance DECIMAL unsigned precision 10 scale 2, address_id INT, foreign key (address_id) references addresses (id) on delete CASCADE, unique name_unique (name), CHECK (balance >= 0)) ENGINE=InnoDB",
+		"create table if not exists logs (user_id INT unique, message TEXT, primary key (user_id))",
+		"alter table users (add column verified BOOL, rename column name to fullname, drop column address_id)",
+		"rename table trxs to transactions",
+		"drop table if exists logs",
+		"create unique index fullname_idx on users (fullname, verified) where.Eq(\"verified\", true)",
+		"drop index if exists fullname_idx on users",
+		"execute raw command: UPDATE users SET verified=true;",
+		"run go code",
+	}, schema.Describe())
+}
+
+func TestSchema_NoTransaction(t *testing.T) {
+	var schema Schema
+
+	assert.True(t, schema.Transactional())
+
+	schema.NoTransaction()
+	assert.False(t, schema.Transactional())
+}
+
 func TestRaw(t *testing.T) {
 	var schema Schema
 
@@ -0,0 +1,148 @@
+// Package seed provides registry and runner for idempotent database seeds.
+// Every seed is only run once, the name of seeds that already ran is stored in seed table.
+package seed
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-rel/rel"
+)
+
+const seedTable = "rel_seeds"
+
+// Func of a seed.
+type Func func(ctx context.Context, repo rel.Repository) error
+
+type seed struct {
+	name string
+	fn   Func
+}
+
+type record struct {
+	ID        int
+	Name      string
+	CreatedAt time.Time
+}
+
+var (
+	registry []seed
+)
+
+// Register a seed globally, registered seeds are included in every Seeder created after.
+// Seeds are run in the order they are registered.
+func Register(name string, fn Func) {
+	registry = register(registry, name, fn)
+}
+
+func register(seeds []seed, name string, fn Func) []seed {
+	for i := range seeds {
+		if seeds[i].name == name {
+			panic("rel: duplicate seed: " + name)
+		}
+	}
+
+	return append(seeds, seed{name: name, fn: fn})
+}
+
+// Seeder runs registered seeds that haven't been run.
+type Seeder struct {
+	repo         rel.Repository
+	instrumenter rel.Instrumenter
+	seeds        []seed
+	table        string
+	tableExists  bool
+}
+
+// Instrumentation function.
+func (s *Seeder) Instrumentation(instrumenter rel.Instrumenter) {
+	s.instrumenter = instrumenter
+}
+
+// Table sets custom table name used to store seeds that already ran.
+func (s *Seeder) Table(name string) {
+	s.table = name
+	s.tableExists = false
+}
+
+// Register a seed to this seeder only.
+func (s *Seeder) Register(name string, fn Func) {
+	s.seeds = register(s.seeds, name, fn)
+}
+
+// Run pending seeds, each seed is run inside its own transaction.
+func (s *Seeder) Run(ctx context.Context) {
+	var (
+		records []record
+		ran     = make(map[string]bool)
+	)
+
+	if !s.tableExists {
+		check(s.repo.Adapter(ctx).Apply(ctx, s.buildTableDefinition()))
+		s.tableExists = true
+	}
+
+	s.repo.MustFindAll(ctx, &records, rel.From(s.table).UsePrimary())
+	for i := range records {
+		ran[records[i].Name] = true
+	}
+
+	for _, sd := range s.seeds {
+		if ran[sd.name] {
+			continue
+		}
+
+		var (
+			fn     = sd.fn
+			name   = sd.name
+			finish = s.instrumenter.Observe(ctx, "seed", name)
+		)
+
+		err := s.repo.Transaction(ctx, func(ctx context.Context) error {
+			if err := fn(ctx, s.repo); err != nil {
+				return err
+			}
+
+			return s.insertRecord(ctx, name)
+		})
+
+		finish(err)
+		check(err)
+	}
+}
+
+func (s Seeder) buildTableDefinition() rel.Table {
+	var schema rel.Schema
+	schema.CreateTableIfNotExists(s.table, func(t *rel.Table) {
+		t.ID("id")
+		t.String("name", rel.Unique(true))
+		t.DateTime("created_at")
+	})
+
+	return schema.Migrations[0].(rel.Table)
+}
+
+func (s *Seeder) insertRecord(ctx context.Context, name string) error {
+	mutates := map[string]rel.Mutate{
+		"name":       rel.Set("name", name),
+		"created_at": rel.Set("created_at", rel.CurrentTime(s.repo)),
+	}
+
+	_, err := s.repo.Adapter(ctx).Insert(ctx, rel.From(s.table), "id", mutates, rel.OnConflict{})
+	return err
+}
+
+// New seeder with globally registered seeds.
+func New(repo rel.Repository) Seeder {
+	return Seeder{
+		repo:  repo,
+		seeds: append([]seed(nil), registry...),
+		table: seedTable,
+	}
+}
+
+func check(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
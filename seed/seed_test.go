@@ -0,0 +1,34 @@
+package seed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-rel/rel"
+	"github.com/stretchr/testify/assert"
+)
+
+func noop(ctx context.Context, repo rel.Repository) error {
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	defer func() { registry = nil }()
+
+	Register("users", noop)
+	Register("tags", noop)
+
+	assert.Panics(t, func() {
+		Register("users", noop)
+	})
+
+	seeder := New(nil)
+	seeder.Register("posts", noop)
+
+	assert.Len(t, registry, 2)
+	assert.Equal(t, []string{"users", "tags", "posts"}, []string{seeder.seeds[0].name, seeder.seeds[1].name, seeder.seeds[2].name})
+	assert.Equal(t, seedTable, seeder.table)
+
+	seeder.Table("seeds")
+	assert.Equal(t, "seeds", seeder.table)
+}
@@ -26,19 +26,19 @@ type Structset struct {
 func (s Structset) Apply(doc *Document, mut *Mutation) {
 	var (
 		pFields = s.doc.PrimaryFields()
-		t       = Now()
+		t       = mut.now()
 	)
 
 	for _, field := range s.doc.Fields() {
 		switch field {
-		case "created_at", "inserted_at":
+		case doc.data.createdAt:
 			if doc.Flag(HasCreatedAt) {
 				if value, ok := doc.Value(field); ok && value.(time.Time).IsZero() {
 					s.set(doc, mut, field, t, true)
 					continue
 				}
 			}
-		case "updated_at":
+		case doc.data.updatedAt:
 			if doc.Flag(HasUpdatedAt) {
 				s.set(doc, mut, field, t, true)
 				continue
@@ -100,7 +100,7 @@ func (s Structset) buildAssoc(field string, mut *Mutation) {
 		doc, _ = assoc.Document()
 	)
 
-	mut.SetAssoc(field, Apply(doc, newStructset(doc, s.skipZero)))
+	mut.SetAssoc(field, applyMutators(doc, mut.clock, true, true, newStructset(doc, s.skipZero)))
 }
 
 func (s Structset) buildAssocMany(field string, mut *Mutation) {
@@ -119,7 +119,7 @@ func (s Structset) buildAssocMany(field string, mut *Mutation) {
 			doc = col.Get(i)
 		)
 
-		muts[i] = Apply(doc, newStructset(doc, s.skipZero))
+		muts[i] = applyMutators(doc, mut.clock, true, true, newStructset(doc, s.skipZero))
 	}
 
 	mut.SetAssoc(field, muts...)
@@ -170,6 +170,59 @@ func TestStructset_invalidCreatedAtType(t *testing.T) {
 	assert.Equal(t, mutation, Apply(doc, NewStructset(&user, false)))
 }
 
+func TestStructset_taggedTimestamps(t *testing.T) {
+	type tmp struct {
+		ID       int
+		Name     string
+		MadeAt   time.Time `db:"made_at,created"`
+		Modified time.Time `db:"modified,updated"`
+	}
+
+	var (
+		record   = tmp{ID: 1, Name: "Luffy"}
+		doc      = NewDocument(&record)
+		mutation = Mutation{
+			Cascade: true,
+			Mutates: map[string]Mutate{
+				"id":       Set("id", 1),
+				"name":     Set("name", "Luffy"),
+				"made_at":  Set("made_at", Now()),
+				"modified": Set("modified", Now()),
+			},
+		}
+	)
+
+	assert.Equal(t, mutation, Apply(doc, NewStructset(&record, false)))
+	assert.Equal(t, Now(), record.MadeAt)
+	assert.Equal(t, Now(), record.Modified)
+}
+
+func TestStructset_clock(t *testing.T) {
+	var (
+		now  = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		user = User{
+			ID:   1,
+			Name: "Luffy",
+			Address: Address{
+				Street: "Grove Street",
+			},
+		}
+		doc      = NewDocument(&user)
+		mutation = applyMutators(doc, func() time.Time { return now }, true, true)
+	)
+
+	assert.Equal(t, map[string]Mutate{
+		"id":         Set("id", 1),
+		"name":       Set("name", "Luffy"),
+		"age":        Set("age", 0),
+		"created_at": Set("created_at", now),
+		"updated_at": Set("updated_at", now),
+	}, mutation.Mutates)
+	assert.Equal(t, now, user.CreatedAt)
+	assert.Equal(t, now, user.UpdatedAt)
+	assert.Equal(t, now, mutation.Assoc["address"].Mutations[0].now())
+}
+
 func TestStructset_differentStruct(t *testing.T) {
 	type UserTmp struct {
 		ID   int
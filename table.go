@@ -1,5 +1,9 @@
 package rel
 
+import (
+	"strings"
+)
+
 // TableDefinition interface.
 type TableDefinition interface {
 	internalTableDefinition()
@@ -124,8 +128,84 @@ func (t Table) description() string {
 	return t.Op.String() + " table " + t.Name
 }
 
+func (t Table) detail() string {
+	var (
+		buffer strings.Builder
+	)
+
+	buffer.WriteString(t.Op.String())
+	buffer.WriteString(" table ")
+
+	if t.Optional {
+		if t.Op == SchemaCreate {
+			buffer.WriteString("if not exists ")
+		} else if t.Op == SchemaDrop {
+			buffer.WriteString("if exists ")
+		}
+	}
+
+	buffer.WriteString(t.Name)
+
+	if t.Op == SchemaRename {
+		buffer.WriteString(" to ")
+		buffer.WriteString(t.Rename)
+	}
+
+	if len(t.Definitions) > 0 {
+		buffer.WriteString(" (")
+		for i, def := range t.Definitions {
+			if i > 0 {
+				buffer.WriteString(", ")
+			}
+
+			switch v := def.(type) {
+			case Column:
+				buffer.WriteString(v.detail(t.Op == SchemaAlter))
+			case Key:
+				buffer.WriteString(v.detail())
+			case Raw:
+				buffer.WriteString(string(v))
+			}
+		}
+		buffer.WriteString(")")
+	}
+
+	writeOptions(&buffer, t.Options)
+	return buffer.String()
+}
+
 func (t Table) internalMigration() {}
 
+func (t Table) reverse() (Table, error) {
+	switch t.Op {
+	case SchemaCreate:
+		return dropTable(t.Name, []TableOption{Optional(t.Optional)}), nil
+	case SchemaRename:
+		return renameTable(t.Rename, t.Name, nil), nil
+	case SchemaAlter:
+		at := alterTable(t.Name, nil)
+		for i := len(t.Definitions) - 1; i >= 0; i-- {
+			column, ok := t.Definitions[i].(Column)
+			if !ok {
+				return Table{}, irreversibleError("alter table " + t.Name + " definition")
+			}
+
+			switch column.Op {
+			case SchemaCreate:
+				at.DropColumn(column.Name)
+			case SchemaRename:
+				at.RenameColumn(column.Rename, column.Name)
+			default:
+				return Table{}, irreversibleError(column.Op.String() + " column " + column.Name + " on " + t.Name)
+			}
+		}
+
+		return at.Table, nil
+	default:
+		return Table{}, irreversibleError(t.description())
+	}
+}
+
 // AlterTable Migrator.
 type AlterTable struct {
 	Table
@@ -151,6 +231,45 @@ func createTable(name string, options []TableOption) Table {
 	return table
 }
 
+// createVersionTable copies columns of base table and returns the table and primary columns of base table.
+func createVersionTable(name string, base Table, options []TableOption) (Table, []string) {
+	var (
+		table     = createTable(name, options)
+		primaries []string
+	)
+
+	for _, def := range base.Definitions {
+		switch v := def.(type) {
+		case Column:
+			if v.Primary {
+				primaries = append(primaries, v.Name)
+			}
+
+			switch v.Type {
+			case ID:
+				v.Type = Int
+				v.Unsigned = true
+			case BigID:
+				v.Type = BigInt
+				v.Unsigned = true
+			}
+
+			v.Primary = false
+			v.Unique = false
+			table.Definitions = append(table.Definitions, v)
+		case Key:
+			if v.Type == PrimaryKey {
+				primaries = append(primaries, v.Columns...)
+			}
+		}
+	}
+
+	table.DateTime("valid_from")
+	table.DateTime("valid_to")
+
+	return table, primaries
+}
+
 func createTableIfNotExists(name string, options []TableOption) Table {
 	table := createTable(name, options)
 	table.Optional = true
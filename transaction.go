@@ -0,0 +1,269 @@
+package rel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IsolationLevel of a transaction.
+// The value is compatible with database/sql IsolationLevel.
+type IsolationLevel int
+
+const (
+	// DefaultIsolation uses default isolation level of the database.
+	DefaultIsolation IsolationLevel = iota
+	// ReadUncommitted isolation level.
+	ReadUncommitted
+	// ReadCommitted isolation level.
+	ReadCommitted
+	// WriteCommitted isolation level.
+	WriteCommitted
+	// RepeatableRead isolation level.
+	RepeatableRead
+	// Snapshot isolation level.
+	Snapshot
+	// Serializable isolation level.
+	Serializable
+	// Linearizable isolation level.
+	Linearizable
+)
+
+// String representation of the isolation level.
+func (il IsolationLevel) String() string {
+	switch il {
+	case DefaultIsolation:
+		return "Default"
+	case ReadUncommitted:
+		return "ReadUncommitted"
+	case ReadCommitted:
+		return "ReadCommitted"
+	case WriteCommitted:
+		return "WriteCommitted"
+	case RepeatableRead:
+		return "RepeatableRead"
+	case Snapshot:
+		return "Snapshot"
+	case Serializable:
+		return "Serializable"
+	case Linearizable:
+		return "Linearizable"
+	default:
+		return fmt.Sprintf("IsolationLevel(%d)", il)
+	}
+}
+
+func (il IsolationLevel) applyTransaction(config *transactionConfig) {
+	config.options.Isolation = il
+}
+
+// TransactionOptions passed to adapter when beginning a transaction.
+type TransactionOptions struct {
+	Isolation        IsolationLevel
+	ReadOnly         bool
+	DeferConstraints bool
+}
+
+// String representation of transaction options.
+func (to TransactionOptions) String() string {
+	var (
+		strs []string
+	)
+
+	if to.Isolation != DefaultIsolation {
+		strs = append(strs, "rel.Isolation(rel."+to.Isolation.String()+")")
+	}
+
+	if to.ReadOnly {
+		strs = append(strs, "rel.ReadOnly(true)")
+	}
+
+	if to.DeferConstraints {
+		strs = append(strs, "rel.DeferConstraints(true)")
+	}
+
+	return strings.Join(strs, ", ")
+}
+
+// TransactionOption interface.
+// Available options are: Isolation, ReadOnly, DeferConstraints, Retry.
+type TransactionOption interface {
+	applyTransaction(config *transactionConfig)
+}
+
+type transactionConfig struct {
+	options TransactionOptions
+	retry   retry
+}
+
+func applyTransactionOptions(options []TransactionOption) transactionConfig {
+	var (
+		config transactionConfig
+	)
+
+	for i := range options {
+		options[i].applyTransaction(&config)
+	}
+
+	return config
+}
+
+// Isolation set isolation level of the transaction.
+func Isolation(level IsolationLevel) TransactionOption {
+	return level
+}
+
+// ReadOnly set transaction as read only.
+type ReadOnly bool
+
+func (ro ReadOnly) applyTransaction(config *transactionConfig) {
+	config.options.ReadOnly = bool(ro)
+}
+
+// DeferConstraints set deferrable constraints to be checked when the transaction is committed.
+type DeferConstraints bool
+
+func (dc DeferConstraints) applyTransaction(config *transactionConfig) {
+	config.options.DeferConstraints = bool(dc)
+}
+
+type retry struct {
+	max     int
+	backoff time.Duration
+}
+
+func (r retry) applyTransaction(config *transactionConfig) {
+	config.retry = r
+}
+
+// String representation.
+func (r retry) String() string {
+	return fmt.Sprintf("rel.Retry(%d, %s)", r.max, r.backoff)
+}
+
+// wait before the given retry attempt, backoff is doubled for each attempt.
+func (r retry) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(r.backoff << uint(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Retry re-runs transaction function from scratch up to max times when it fails because of RetryableError.
+// Backoff is waited before the first retry and doubled for each subsequent retry.
+// Retry only applies to the outermost transaction, nested transaction is never retried.
+func Retry(max int, backoff time.Duration) TransactionOption {
+	return retry{max: max, backoff: backoff}
+}
+
+func isRetryable(err error) bool {
+	var (
+		re RetryableError
+	)
+
+	return errors.As(err, &re)
+}
+
+var (
+	hooksCtxKey   contextKey = 1
+	optionsCtxKey contextKey = 3
+)
+
+type transactionHooks struct {
+	lock     sync.Mutex
+	commit   []func(ctx context.Context)
+	rollback []func(ctx context.Context)
+}
+
+// run commit or rollback callbacks in the order they're registered.
+func (th *transactionHooks) run(ctx context.Context, committed bool) {
+	th.lock.Lock()
+	fns := th.rollback
+	if committed {
+		fns = th.commit
+	}
+	th.commit, th.rollback = nil, nil
+	th.lock.Unlock()
+
+	for i := range fns {
+		fns[i](ctx)
+	}
+}
+
+// release moves callbacks registered inside a released savepoint or committed nested transaction to the enclosing hooks.
+func (th *transactionHooks) release(savepoint *transactionHooks) {
+	savepoint.lock.Lock()
+	commit, rollback := savepoint.commit, savepoint.rollback
+	savepoint.lock.Unlock()
+
+	th.lock.Lock()
+	th.commit = append(th.commit, commit...)
+	th.rollback = append(th.rollback, rollback...)
+	th.lock.Unlock()
+}
+
+// rollbackTo drops commit callbacks registered inside a rolled back savepoint or nested transaction,
+// while its rollback callbacks are run when the outermost transaction finishes, whether it's committed or not.
+func (th *transactionHooks) rollbackTo(savepoint *transactionHooks) {
+	savepoint.lock.Lock()
+	rollback := savepoint.rollback
+	savepoint.lock.Unlock()
+
+	th.lock.Lock()
+	th.commit = append(th.commit, rollback...)
+	th.rollback = append(th.rollback, rollback...)
+	th.lock.Unlock()
+}
+
+// fetchTransactionHooks returns hooks of the outermost transaction and true when ctx is inside a transaction,
+// which is when ctx carries transaction adapter, the same check used to decide whether a transaction is nested.
+func fetchTransactionHooks(ctx context.Context) (*transactionHooks, bool) {
+	if _, ok := ctx.Value(ctxKey).(Adapter); !ok {
+		return nil, false
+	}
+
+	if hooks, ok := ctx.Value(hooksCtxKey).(*transactionHooks); ok {
+		return hooks, true
+	}
+
+	// transaction is managed outside of repository, callbacks are discarded.
+	return &transactionHooks{}, true
+}
+
+// AfterCommit registers fn to be called after the outermost transaction inside ctx is committed.
+// fn is called immediately when ctx is not inside a transaction.
+// fn receives context of the outermost transaction caller, so it doesn't use the finished transaction.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	hooks, ok := fetchTransactionHooks(ctx)
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	hooks.lock.Lock()
+	hooks.commit = append(hooks.commit, fn)
+	hooks.lock.Unlock()
+}
+
+// AfterRollback registers fn to be called after the outermost transaction inside ctx is rolled back,
+// including when the commit itself fails.
+// fn is called immediately when ctx is not inside a transaction.
+func AfterRollback(ctx context.Context, fn func(ctx context.Context)) {
+	hooks, ok := fetchTransactionHooks(ctx)
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	hooks.lock.Lock()
+	hooks.rollback = append(hooks.rollback, fn)
+	hooks.lock.Unlock()
+}
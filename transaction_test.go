@@ -0,0 +1,54 @@
+package rel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsolationLevel_String(t *testing.T) {
+	assert.Equal(t, "Default", DefaultIsolation.String())
+	assert.Equal(t, "ReadUncommitted", ReadUncommitted.String())
+	assert.Equal(t, "ReadCommitted", ReadCommitted.String())
+	assert.Equal(t, "WriteCommitted", WriteCommitted.String())
+	assert.Equal(t, "RepeatableRead", RepeatableRead.String())
+	assert.Equal(t, "Snapshot", Snapshot.String())
+	assert.Equal(t, "Serializable", Serializable.String())
+	assert.Equal(t, "Linearizable", Linearizable.String())
+	assert.Equal(t, "IsolationLevel(10)", IsolationLevel(10).String())
+}
+
+func TestTransactionOptions(t *testing.T) {
+	config := applyTransactionOptions([]TransactionOption{
+		Isolation(RepeatableRead),
+		ReadOnly(true),
+		DeferConstraints(true),
+		Retry(3, time.Millisecond),
+	})
+	options := config.options
+
+	assert.Equal(t, TransactionOptions{
+		Isolation:        RepeatableRead,
+		ReadOnly:         true,
+		DeferConstraints: true,
+	}, options)
+	assert.Equal(t, "rel.Isolation(rel.RepeatableRead), rel.ReadOnly(true), rel.DeferConstraints(true)", options.String())
+	assert.Equal(t, "", TransactionOptions{}.String())
+	assert.Equal(t, retry{max: 3, backoff: time.Millisecond}, config.retry)
+	assert.Equal(t, "rel.Retry(3, 1ms)", config.retry.String())
+}
+
+func TestRetry_wait(t *testing.T) {
+	var (
+		r           = retry{max: 3, backoff: time.Millisecond}
+		ctx, cancel = context.WithCancel(context.TODO())
+	)
+
+	assert.Nil(t, r.wait(ctx, 1))
+
+	cancel()
+	r.backoff = time.Hour
+	assert.Equal(t, context.Canceled, r.wait(ctx, 0))
+}